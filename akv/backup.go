@@ -0,0 +1,36 @@
+package akv
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+)
+
+// Backup returns the protected backup blob of a secret. The blob holds
+// every version of the secret and can only be restored into a vault of
+// the same subscription and geography.
+func (c *Client) Backup(ctx context.Context, name string) ([]byte, error) {
+	if name == "" {
+		return nil, newError("backup", c.vault, name, ErrMissingSecretName)
+	}
+
+	resp, err := c.client.BackupSecret(ctx, name, nil)
+	if err != nil {
+		return nil, newError("backup", c.vault, name, err)
+	}
+
+	return resp.Value, nil
+}
+
+// Restore restores a secret, with all of its versions, from a blob created
+// by Backup. It fails with a conflict when the secret already exists, see
+// IsConflict.
+func (c *Client) Restore(ctx context.Context, blob []byte) (*SecretProperties, error) {
+	resp, err := c.client.RestoreSecret(ctx, azsecrets.RestoreSecretParameters{SecretBackup: blob}, nil)
+	if err != nil {
+		return nil, newError("restore", c.vault, "", err)
+	}
+
+	props := newSecret(resp.Secret).SecretProperties
+	return &props, nil
+}
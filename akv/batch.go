@@ -0,0 +1,65 @@
+package akv
+
+import (
+	"context"
+	"sync"
+)
+
+// GetMany reads the latest version of each named secret, reading up to
+// concurrency secrets at the same time. The result is in the order of
+// names. The first error cancels the remaining reads.
+func (c *Client) GetMany(ctx context.Context, names []string, concurrency int) ([]*Secret, error) {
+	secrets := make([]*Secret, len(names))
+	err := forEach(ctx, len(names), concurrency, func(ctx context.Context, i int) error {
+		secret, err := c.Get(ctx, names[i], "")
+		if err != nil {
+			return err
+		}
+
+		secrets[i] = secret
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return secrets, nil
+}
+
+// forEach calls fn for 0..n-1 with at most concurrency calls running at the
+// same time and returns the first error. The context passed to fn is
+// cancelled once a call fails.
+func forEach(ctx context.Context, n int, concurrency int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		sem      = make(chan struct{}, max(concurrency, 1))
+	)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			if err := fn(ctx, i); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	return firstErr
+}
@@ -0,0 +1,481 @@
+// Package akv reads, writes and generates secrets in Azure Key Vault.
+//
+// It is the library behind the hx-secrets-akv command line tool and can be
+// used directly from Go programs:
+//
+//	client, err := akv.NewClient("myvault", cred, nil)
+//	secret, err := client.Get(ctx, "db-password", "")
+package akv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+)
+
+// DefaultVaultSuffix is the DNS suffix of vaults in the Azure public cloud.
+const DefaultVaultSuffix = ".vault.azure.net"
+
+// Client is a key vault client bound to a single vault.
+type Client struct {
+	vault  string
+	url    string
+	client *azsecrets.Client
+}
+
+// ClientOptions contains optional settings for Client.
+type ClientOptions struct {
+	azsecrets.ClientOptions
+
+	// Cloud selects the vault DNS suffix used for vault names. Host names
+	// and urls are used as given. The public cloud is used when empty.
+	Cloud Cloud
+
+	// Endpoint, when set, is used as the vault url instead of the url
+	// derived from the vault name, for example to reach a local emulator
+	// or a proxy.
+	Endpoint string
+}
+
+// SetOptions contains the optional properties written with a new secret
+// version.
+type SetOptions struct {
+	ContentType string
+	Enabled     *bool
+	NotBefore   *time.Time
+	Expires     *time.Time
+	Tags        map[string]string
+}
+
+// UpdateOptions contains the properties changed by Client.Update. Nil
+// fields are left as they are; an empty, non-nil Tags map removes every
+// tag.
+type UpdateOptions struct {
+	ContentType *string
+	Enabled     *bool
+	NotBefore   *time.Time
+	Expires     *time.Time
+	Tags        map[string]string
+}
+
+// RollbackOptions contains optional settings for Client.Rollback.
+type RollbackOptions struct {
+	// DisableCurrent disables the version that was current before the
+	// rollback.
+	DisableCurrent bool
+}
+
+// DeleteOptions contains optional settings for Client.Delete.
+type DeleteOptions struct {
+	// Purge permanently deletes the secret once the soft delete completes.
+	Purge bool
+}
+
+// ResolveOptions contains optional settings for Client.Resolve.
+type ResolveOptions struct {
+	// Version of the secret to read. The latest version is used when empty.
+	Version string
+	// Generate controls the value created when the secret does not exist
+	// or has expired and is tagged with auto-rotate=true.
+	Generate GenerateOptions
+	// Hook is called before a regenerated expired secret is enabled, see
+	// Client.Rotate. It is not called for secrets that are created.
+	Hook RotationHook
+	// Profiles resolves the charset of the rotation policy of an expired
+	// secret, see RotateOptions.
+	Profiles ProfileLookup
+}
+
+// NewClient creates a client for the vault. The vault may be a vault name
+// such as "myvault", a host name or a https url.
+func NewClient(vault string, credential azcore.TokenCredential, options *ClientOptions) (*Client, error) {
+	if options == nil {
+		options = &ClientOptions{}
+	}
+
+	if vault == "" {
+		if options.Endpoint == "" {
+			return nil, newError("connect", "", "", ErrMissingVaultName)
+		}
+		vault = options.Endpoint
+	}
+
+	vaultURL := options.Cloud.VaultURL(vault)
+	if options.Endpoint != "" {
+		vaultURL = strings.TrimSuffix(options.Endpoint, "/")
+	}
+	client, err := azsecrets.NewClient(vaultURL, credential, &options.ClientOptions)
+	if err != nil {
+		return nil, &Error{Op: "connect", Vault: vault, Kind: ErrClientFailed, Err: err}
+	}
+
+	return &Client{
+		vault:  vault,
+		url:    vaultURL,
+		client: client,
+	}, nil
+}
+
+// VaultURL returns the https url of a vault given its name, host name
+// or url. Vault names are resolved in the public cloud.
+func VaultURL(vault string) string {
+	return AzurePublic.VaultURL(vault)
+}
+
+// Vault returns the vault the client was created for.
+func (c *Client) Vault() string {
+	return c.vault
+}
+
+// URL returns the https url of the vault.
+func (c *Client) URL() string {
+	return c.url
+}
+
+// Azure returns the underlying azsecrets client.
+func (c *Client) Azure() *azsecrets.Client {
+	return c.client
+}
+
+// Get reads a secret. The latest version is returned when version is empty.
+func (c *Client) Get(ctx context.Context, name, version string) (*Secret, error) {
+	if name == "" {
+		return nil, newError("get", c.vault, name, ErrMissingSecretName)
+	}
+
+	resp, err := c.client.GetSecret(ctx, name, version, nil)
+	if err != nil {
+		return nil, newError("get", c.vault, name, err)
+	}
+
+	return newSecret(resp.Secret), nil
+}
+
+// Set writes value as a new version of the secret.
+func (c *Client) Set(ctx context.Context, name, value string, options *SetOptions) (*Secret, error) {
+	if name == "" {
+		return nil, newError("set", c.vault, name, ErrMissingSecretName)
+	}
+
+	params := azsecrets.SetSecretParameters{
+		Value: &value,
+	}
+
+	if options != nil {
+		if options.ContentType != "" {
+			params.ContentType = &options.ContentType
+		}
+
+		if options.Enabled != nil || options.NotBefore != nil || options.Expires != nil {
+			params.SecretAttributes = &azsecrets.SecretAttributes{
+				Enabled:   options.Enabled,
+				NotBefore: options.NotBefore,
+				Expires:   options.Expires,
+			}
+		}
+
+		params.Tags = toTags(options.Tags)
+	}
+
+	resp, err := c.client.SetSecret(ctx, name, params, nil)
+	if err != nil {
+		return nil, newError("set", c.vault, name, err)
+	}
+
+	return newSecret(resp.Secret), nil
+}
+
+// Update changes the properties of a version of a secret without creating
+// a new version. The latest version is updated when version is empty.
+func (c *Client) Update(ctx context.Context, name, version string, options *UpdateOptions) (*SecretProperties, error) {
+	if name == "" {
+		return nil, newError("update", c.vault, name, ErrMissingSecretName)
+	}
+
+	params := azsecrets.UpdateSecretPropertiesParameters{}
+	if options != nil {
+		params.ContentType = options.ContentType
+		if options.Enabled != nil || options.NotBefore != nil || options.Expires != nil {
+			params.SecretAttributes = &azsecrets.SecretAttributes{
+				Enabled:   options.Enabled,
+				NotBefore: options.NotBefore,
+				Expires:   options.Expires,
+			}
+		}
+
+		params.Tags = toTags(options.Tags)
+	}
+
+	resp, err := c.client.UpdateSecretProperties(ctx, name, version, params, nil)
+	if err != nil {
+		return nil, newError("update", c.vault, name, err)
+	}
+
+	props := newSecret(resp.Secret).SecretProperties
+	return &props, nil
+}
+
+// Rollback writes the value, content type and tags of an earlier version
+// as the new current version of a secret. The target is a version id, or
+// -N for the version N versions before the current one.
+func (c *Client) Rollback(ctx context.Context, name, target string, options *RollbackOptions) (*Secret, error) {
+	if options == nil {
+		options = &RollbackOptions{}
+	}
+
+	versions, err := c.Versions(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(versions) == 0 {
+		return nil, newError("rollback", c.vault, name, ErrSecretNotFound)
+	}
+
+	// the latest version is the current one, even when an older version
+	// has a later creation time.
+	if latest, err := c.Get(ctx, name, ""); err == nil {
+		for i, props := range versions {
+			if props.Version == latest.Version {
+				versions = append([]SecretProperties{props}, append(versions[:i:i], versions[i+1:]...)...)
+				break
+			}
+		}
+	}
+
+	current := versions[0]
+	version := target
+	if strings.HasPrefix(target, "-") {
+		n, err := strconv.Atoi(target[1:])
+		if err != nil || n < 1 {
+			return nil, newError("rollback", c.vault, name, fmt.Errorf("invalid version offset %q", target))
+		}
+
+		if n >= len(versions) {
+			return nil, newError("rollback", c.vault, name, fmt.Errorf("the secret has only %d versions", len(versions)))
+		}
+
+		version = versions[n].Version
+	}
+
+	if version == "" {
+		return nil, newError("rollback", c.vault, name, errors.New("target version is required"))
+	}
+
+	if strings.EqualFold(version, current.Version) {
+		return nil, newError("rollback", c.vault, name, fmt.Errorf("version %s is already the current version", version))
+	}
+
+	old, err := c.Get(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := c.Set(ctx, name, old.Value, &SetOptions{
+		ContentType: old.ContentType,
+		Tags:        old.Tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if options.DisableCurrent {
+		disabled := false
+		if _, err := c.Update(ctx, name, current.Version, &UpdateOptions{Enabled: &disabled}); err != nil {
+			return secret, err
+		}
+	}
+
+	return secret, nil
+}
+
+// List returns the properties of the secrets in the vault whose name
+// matches pattern. The pattern uses filepath.Match syntax and an empty
+// pattern matches every secret.
+func (c *Client) List(ctx context.Context, pattern string) ([]SecretProperties, error) {
+	if pattern != "" {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, newError("list", c.vault, pattern, ErrInvalidPattern)
+		}
+	}
+
+	list := []SecretProperties{}
+	pager := c.client.NewListSecretPropertiesPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, newError("list", c.vault, "", err)
+		}
+
+		for _, item := range page.Value {
+			props := newProperties(item.ID, item.ContentType, item.Attributes, item.Tags, item.Managed)
+			if pattern != "" {
+				if ok, _ := filepath.Match(pattern, props.Name); !ok {
+					continue
+				}
+			}
+
+			list = append(list, props)
+		}
+	}
+
+	return list, nil
+}
+
+// Versions returns the properties of every version of a secret, newest
+// first.
+func (c *Client) Versions(ctx context.Context, name string) ([]SecretProperties, error) {
+	if name == "" {
+		return nil, newError("versions", c.vault, name, ErrMissingSecretName)
+	}
+
+	list := []SecretProperties{}
+	pager := c.client.NewListSecretPropertiesVersionsPager(name, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, newError("versions", c.vault, name, err)
+		}
+
+		for _, item := range page.Value {
+			list = append(list, newProperties(item.ID, item.ContentType, item.Attributes, item.Tags, item.Managed))
+		}
+	}
+
+	// the service does not list versions in a defined order, so versions
+	// created in the same second are ordered by their last update and then
+	// by version id.
+	sort.Slice(list, func(i, j int) bool {
+		if c := compareNewest(list[i].Created, list[j].Created); c != 0 {
+			return c < 0
+		}
+		if c := compareNewest(list[i].Updated, list[j].Updated); c != 0 {
+			return c < 0
+		}
+		return list[i].Version > list[j].Version
+	})
+
+	return list, nil
+}
+
+// compareNewest orders times newest first with nil times last.
+func compareNewest(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	return b.Compare(*a)
+}
+
+// Delete soft deletes a secret. When options.Purge is set, Delete waits up
+// to DefaultRecoverTimeout for the deletion to complete and then purges the
+// secret.
+func (c *Client) Delete(ctx context.Context, name string, options *DeleteOptions) error {
+	if name == "" {
+		return newError("delete", c.vault, name, ErrMissingSecretName)
+	}
+
+	resp, err := c.client.DeleteSecret(ctx, name, nil)
+	if err != nil {
+		return newError("delete", c.vault, name, err)
+	}
+
+	if options == nil || !options.Purge || resp.ScheduledPurgeDate == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultRecoverTimeout)
+	defer cancel()
+
+	// deletion completes asynchronously and the deleted secret cannot be
+	// purged until it shows up as deleted.
+	for {
+		_, err := c.client.GetDeletedSecret(ctx, name, nil)
+		if err == nil {
+			break
+		}
+
+		if !IsNotFound(err) {
+			return newError("delete", c.vault, name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return newError("delete", c.vault, name, ctx.Err())
+		case <-time.After(time.Second):
+		}
+	}
+
+	return c.Purge(ctx, name)
+}
+
+// Purge permanently deletes a soft deleted secret.
+func (c *Client) Purge(ctx context.Context, name string) error {
+	if name == "" {
+		return newError("purge", c.vault, name, ErrMissingSecretName)
+	}
+
+	_, err := c.client.PurgeDeletedSecret(ctx, name, nil)
+	if err != nil {
+		return newError("purge", c.vault, name, err)
+	}
+
+	return nil
+}
+
+// Resolve reads a secret and creates it with a generated value when it does
+// not exist. An expired secret is regenerated when it is tagged with
+// auto-rotate=true, otherwise ErrSecretExpired is returned. A regenerated
+// secret keeps its tags and follows its rotation policy when it has one.
+func (c *Client) Resolve(ctx context.Context, name string, options *ResolveOptions) (*Secret, error) {
+	if options == nil {
+		options = &ResolveOptions{}
+	}
+
+	secret, err := c.Get(ctx, name, options.Version)
+	if err != nil && !IsNotFound(err) {
+		return nil, err
+	}
+
+	if err == nil {
+		if !secret.IsExpired(time.Now()) {
+			return secret, nil
+		}
+
+		if secret.Tag("auto-rotate") != "true" {
+			return nil, newError("resolve", c.vault, name, ErrSecretExpired)
+		}
+
+		policy, err := ParseRotationPolicy(secret.Tags)
+		if err != nil {
+			return nil, newError("resolve", c.vault, name, err)
+		}
+
+		if policy != nil {
+			return c.rotate(ctx, secret, policy, &RotateOptions{Hook: options.Hook, Profiles: options.Profiles})
+		}
+	}
+
+	value, err := Generate(options.Generate)
+	if err != nil {
+		return nil, newError("resolve", c.vault, name, err)
+	}
+
+	if secret == nil {
+		return c.Set(ctx, name, value, nil)
+	}
+
+	return c.replace(ctx, "resolve", secret, value, &SetOptions{ContentType: secret.ContentType, Tags: secret.Tags}, options.Hook)
+}
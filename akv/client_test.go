@@ -0,0 +1,499 @@
+package akv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// fakeVault is an in-memory key vault that answers the requests of the
+// azsecrets client, including the bearer challenge it starts with.
+type fakeVault struct {
+	mu      sync.Mutex
+	secrets map[string][]fakeVersion
+	deleted map[string][]fakeVersion
+	next    int
+}
+
+type fakeVersion struct {
+	ID          string            `json:"-"`
+	Value       string            `json:"value"`
+	ContentType string            `json:"contentType,omitempty"`
+	Attributes  fakeAttributes    `json:"attributes"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+type fakeAttributes struct {
+	Enabled   *bool  `json:"enabled,omitempty"`
+	NotBefore *int64 `json:"nbf,omitempty"`
+	Expires   *int64 `json:"exp,omitempty"`
+	Created   *int64 `json:"created,omitempty"`
+	Updated   *int64 `json:"updated,omitempty"`
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer test-token" {
+		w.Header().Set("WWW-Authenticate", `Bearer authorization="https://login.microsoftonline.com/tenant", resource="https://vault.azure.net"`)
+		fakeError(w, http.StatusUnauthorized, "Unauthorized", "missing token")
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.Method == http.MethodGet && len(parts) == 1 && parts[0] == "secrets":
+		items := []map[string]any{}
+		for name, versions := range v.secrets {
+			latest := versions[len(versions)-1]
+			items = append(items, map[string]any{"id": fakeID(r, name, ""), "attributes": latest.Attributes, "tags": latest.Tags})
+		}
+		fakeJSON(w, http.StatusOK, map[string]any{"value": items})
+	case r.Method == http.MethodPut && len(parts) == 2 && parts[0] == "secrets":
+		version := fakeVersion{}
+		if err := json.NewDecoder(r.Body).Decode(&version); err != nil {
+			fakeError(w, http.StatusBadRequest, "BadParameter", err.Error())
+			return
+		}
+
+		v.next++
+		now := time.Now().Unix()
+		version.ID = fmt.Sprintf("%032x", v.next)
+		version.Attributes.Created, version.Attributes.Updated = &now, &now
+		if version.Attributes.Enabled == nil {
+			enabled := true
+			version.Attributes.Enabled = &enabled
+		}
+
+		v.secrets[parts[1]] = append(v.secrets[parts[1]], version)
+		fakeJSON(w, http.StatusOK, fakeBundle(r, parts[1], version))
+	case r.Method == http.MethodGet && len(parts) >= 2 && parts[0] == "secrets":
+		version := v.find(parts)
+		if version == nil {
+			fakeError(w, http.StatusNotFound, "SecretNotFound", "A secret with (name/id) "+parts[1]+" was not found in this key vault.")
+			return
+		}
+		fakeJSON(w, http.StatusOK, fakeBundle(r, parts[1], *version))
+	case r.Method == http.MethodPatch && len(parts) >= 2 && parts[0] == "secrets":
+		version := v.find(parts)
+		if version == nil {
+			fakeError(w, http.StatusNotFound, "SecretNotFound", "A secret with (name/id) "+parts[1]+" was not found in this key vault.")
+			return
+		}
+
+		params := struct {
+			ContentType *string           `json:"contentType"`
+			Attributes  *fakeAttributes   `json:"attributes"`
+			Tags        map[string]string `json:"tags"`
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			fakeError(w, http.StatusBadRequest, "BadParameter", err.Error())
+			return
+		}
+
+		if params.ContentType != nil {
+			version.ContentType = *params.ContentType
+		}
+		if a := params.Attributes; a != nil {
+			if a.Enabled != nil {
+				version.Attributes.Enabled = a.Enabled
+			}
+			if a.NotBefore != nil {
+				version.Attributes.NotBefore = a.NotBefore
+			}
+			if a.Expires != nil {
+				version.Attributes.Expires = a.Expires
+			}
+		}
+		if params.Tags != nil {
+			version.Tags = params.Tags
+		}
+
+		bundle := fakeBundle(r, parts[1], *version)
+		delete(bundle, "value")
+		fakeJSON(w, http.StatusOK, bundle)
+	case r.Method == http.MethodDelete && len(parts) == 2 && parts[0] == "secrets":
+		versions, ok := v.secrets[parts[1]]
+		if !ok {
+			fakeError(w, http.StatusNotFound, "SecretNotFound", "A secret with (name/id) "+parts[1]+" was not found in this key vault.")
+			return
+		}
+
+		delete(v.secrets, parts[1])
+		v.deleted[parts[1]] = versions
+		bundle := fakeBundle(r, parts[1], versions[len(versions)-1])
+		bundle["scheduledPurgeDate"] = time.Now().Add(90 * 24 * time.Hour).Unix()
+		fakeJSON(w, http.StatusOK, bundle)
+	case len(parts) == 2 && parts[0] == "deletedsecrets":
+		versions, ok := v.deleted[parts[1]]
+		if !ok {
+			fakeError(w, http.StatusNotFound, "SecretNotFound", "Deleted Secret not found")
+			return
+		}
+
+		if r.Method == http.MethodDelete {
+			delete(v.deleted, parts[1])
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		fakeJSON(w, http.StatusOK, fakeBundle(r, parts[1], versions[len(versions)-1]))
+	default:
+		fakeError(w, http.StatusBadRequest, "BadParameter", r.Method+" "+r.URL.Path)
+	}
+}
+
+// find returns the version of a secrets/<name>[/<version>] path, the
+// latest one when the path has no version.
+func (v *fakeVault) find(parts []string) *fakeVersion {
+	versions := v.secrets[parts[1]]
+	for i := range versions {
+		if len(parts) == 2 && i == len(versions)-1 || len(parts) == 3 && versions[i].ID == parts[2] {
+			return &versions[i]
+		}
+	}
+	return nil
+}
+
+func fakeID(r *http.Request, name, version string) string {
+	id := "https://" + r.Host + "/secrets/" + name
+	if version != "" {
+		id += "/" + version
+	}
+	return id
+}
+
+func fakeBundle(r *http.Request, name string, version fakeVersion) map[string]any {
+	return map[string]any{
+		"id":          fakeID(r, name, version.ID),
+		"value":       version.Value,
+		"contentType": version.ContentType,
+		"attributes":  version.Attributes,
+		"tags":        version.Tags,
+	}
+}
+
+func fakeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func fakeError(w http.ResponseWriter, status int, code, message string) {
+	fakeJSON(w, status, map[string]any{"error": map[string]string{"code": code, "message": message}})
+}
+
+// redirect sends every request to host, so that the client can keep its
+// real vault url.
+type redirect struct {
+	host string
+	next http.RoundTripper
+}
+
+func (t redirect) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Host = t.host
+	return t.next.RoundTrip(req)
+}
+
+type testCredential struct{}
+
+func (testCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "test-token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+// newFakeClient returns a client for the vault myvault that talks to a new
+// fakeVault.
+func newFakeClient(t *testing.T) (*Client, *fakeVault) {
+	t.Helper()
+
+	vault := &fakeVault{secrets: map[string][]fakeVersion{}, deleted: map[string][]fakeVersion{}}
+	srv := httptest.NewTLSServer(vault)
+	t.Cleanup(srv.Close)
+
+	options := &ClientOptions{}
+	options.Transport = &http.Client{Transport: redirect{host: srv.Listener.Addr().String(), next: srv.Client().Transport}}
+	options.DisableChallengeResourceVerification = true
+
+	client, err := NewClient("myvault", testCredential{}, options)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	return client, vault
+}
+
+func TestClientGetSet(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newFakeClient(t)
+
+	expires := time.Now().Add(time.Hour).Truncate(time.Second)
+	first, err := client.Set(ctx, "db-pass", "one", &SetOptions{
+		ContentType: "text/plain",
+		Expires:     &expires,
+		Tags:        map[string]string{"env": "test"},
+	})
+	if err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if first.Name != "db-pass" || first.Version == "" || first.Value != "one" {
+		t.Errorf("Set = %+v", first)
+	}
+
+	if _, err := client.Set(ctx, "db-pass", "two", nil); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	latest, err := client.Get(ctx, "db-pass", "")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if latest.Value != "two" || latest.Version == first.Version || !latest.Enabled {
+		t.Errorf("Get latest = %+v", latest)
+	}
+
+	old, err := client.Get(ctx, "db-pass", first.Version)
+	if err != nil {
+		t.Fatalf("Get version: %v", err)
+	}
+	if old.Value != "one" || old.ContentType != "text/plain" || old.Tag("env") != "test" {
+		t.Errorf("Get version = %+v", old)
+	}
+	if old.Expires == nil || !old.Expires.Equal(expires) {
+		t.Errorf("Get version expires = %v, want %v", old.Expires, expires)
+	}
+	if old.Created == nil || old.Updated == nil {
+		t.Errorf("Get version has no created or updated time: %+v", old)
+	}
+}
+
+func TestClientUpdate(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newFakeClient(t)
+
+	first, err := client.Set(ctx, "db-pass", "one", &SetOptions{ContentType: "text/plain", Tags: map[string]string{"env": "test", "team": "api"}})
+	if err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if _, err := client.Set(ctx, "db-pass", "two", &SetOptions{Tags: map[string]string{"env": "prod"}}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	disabled := false
+	expires := time.Now().Add(time.Hour).Truncate(time.Second)
+	props, err := client.Update(ctx, "db-pass", first.Version, &UpdateOptions{Enabled: &disabled, Expires: &expires})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if props.Version != first.Version || props.Enabled || props.Expires == nil || !props.Expires.Equal(expires) {
+		t.Errorf("Update = %+v, want version %s disabled and expiring at %v", props, first.Version, expires)
+	}
+	if props.ContentType != "text/plain" || props.Tag("team") != "api" {
+		t.Errorf("Update changed properties that were not given: %+v", props)
+	}
+
+	contentType := "application/json"
+	props, err = client.Update(ctx, "db-pass", "", &UpdateOptions{ContentType: &contentType, Tags: map[string]string{}})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if props.Version == first.Version || props.ContentType != contentType || len(props.Tags) != 0 || !props.Enabled {
+		t.Errorf("Update of the current version = %+v", props)
+	}
+
+	if _, err := client.Update(ctx, "missing", "", &UpdateOptions{Enabled: &disabled}); !IsNotFound(err) {
+		t.Errorf("Update missing error = %v, want not found", err)
+	}
+
+	if _, err := client.Update(ctx, "", "", nil); !errors.Is(err, ErrMissingSecretName) {
+		t.Errorf("Update without a name error = %v, want ErrMissingSecretName", err)
+	}
+}
+
+func TestClientList(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newFakeClient(t)
+
+	for _, name := range []string{"db-pass", "db-user", "api-key"} {
+		if _, err := client.Set(ctx, name, "value", nil); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+	}
+
+	tests := []struct {
+		pattern string
+		want    int
+	}{
+		{"", 3},
+		{"db-*", 2},
+		{"api-key", 1},
+		{"none-*", 0},
+	}
+
+	for _, tt := range tests {
+		list, err := client.List(ctx, tt.pattern)
+		if err != nil {
+			t.Errorf("List(%q): %v", tt.pattern, err)
+			continue
+		}
+		if len(list) != tt.want {
+			t.Errorf("List(%q) returned %d secrets, want %d", tt.pattern, len(list), tt.want)
+		}
+	}
+
+	if _, err := client.List(ctx, "[db"); !errors.Is(err, ErrInvalidPattern) {
+		t.Errorf("List with a bad pattern error = %v, want ErrInvalidPattern", err)
+	}
+}
+
+func TestClientDelete(t *testing.T) {
+	ctx := context.Background()
+	client, vault := newFakeClient(t)
+
+	for _, name := range []string{"db-pass", "api-key"} {
+		if _, err := client.Set(ctx, name, "value", nil); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+	}
+
+	if err := client.Delete(ctx, "db-pass", nil); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, ok := vault.deleted["db-pass"]; !ok {
+		t.Error("Delete did not soft delete db-pass")
+	}
+
+	if err := client.Delete(ctx, "api-key", &DeleteOptions{Purge: true}); err != nil {
+		t.Fatalf("Delete with purge: %v", err)
+	}
+	if _, ok := vault.deleted["api-key"]; ok {
+		t.Error("Delete with purge left api-key in the deleted secrets")
+	}
+
+	if err := client.Purge(ctx, "db-pass"); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if len(vault.deleted) != 0 {
+		t.Errorf("deleted secrets after purge = %v, want none", vault.deleted)
+	}
+}
+
+func TestClientResolve(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newFakeClient(t)
+
+	created, err := client.Resolve(ctx, "new-secret", &ResolveOptions{Generate: GenerateOptions{Size: 24}})
+	if err != nil {
+		t.Fatalf("Resolve missing secret: %v", err)
+	}
+	if len(created.Value) != 24 {
+		t.Errorf("Resolve generated %q, want 24 characters", created.Value)
+	}
+
+	again, err := client.Resolve(ctx, "new-secret", nil)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if again.Value != created.Value {
+		t.Errorf("Resolve = %q, want the stored %q", again.Value, created.Value)
+	}
+
+	expired := time.Now().Add(-time.Hour)
+	if _, err := client.Set(ctx, "old", "value", &SetOptions{Expires: &expired}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if _, err := client.Resolve(ctx, "old", nil); !errors.Is(err, ErrSecretExpired) {
+		t.Errorf("Resolve expired secret error = %v, want ErrSecretExpired", err)
+	}
+
+	if _, err := client.Set(ctx, "rotated", "value", &SetOptions{Expires: &expired, Tags: map[string]string{"auto-rotate": "true"}}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	rotated, err := client.Resolve(ctx, "rotated", &ResolveOptions{Generate: GenerateOptions{Size: 16}})
+	if err != nil {
+		t.Fatalf("Resolve auto-rotate secret: %v", err)
+	}
+	if rotated.Value == "value" || len(rotated.Value) != 16 {
+		t.Errorf("Resolve auto-rotate = %q, want a new 16 character value", rotated.Value)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newFakeClient(t)
+
+	if _, err := NewClient("", testCredential{}, nil); !errors.Is(err, ErrMissingVaultName) {
+		t.Errorf("NewClient without a vault error = %v, want ErrMissingVaultName", err)
+	}
+
+	if _, err := client.Get(ctx, "", ""); !errors.Is(err, ErrMissingSecretName) {
+		t.Errorf("Get without a name error = %v, want ErrMissingSecretName", err)
+	}
+
+	_, err := client.Get(ctx, "missing", "")
+	if !IsNotFound(err) || !errors.Is(err, ErrSecretNotFound) {
+		t.Fatalf("Get missing error = %v, want not found", err)
+	}
+
+	var akvErr *Error
+	if !errors.As(err, &akvErr) || akvErr.Op != "get" || akvErr.Vault != "myvault" || akvErr.Name != "missing" {
+		t.Errorf("Get missing error = %#v, want an *Error for get missing in myvault", err)
+	}
+
+	var respErr *azcore.ResponseError
+	if !errors.As(err, &respErr) || respErr.StatusCode != http.StatusNotFound || respErr.ErrorCode != "SecretNotFound" {
+		t.Errorf("Get missing error does not wrap the 404 response: %v", err)
+	}
+
+	if err := client.Delete(ctx, "missing", nil); !IsNotFound(err) {
+		t.Errorf("Delete missing error = %v, want not found", err)
+	}
+
+	if err := client.Purge(ctx, "missing"); !IsNotFound(err) {
+		t.Errorf("Purge missing error = %v, want not found", err)
+	}
+}
+
+func TestIsDisabled(t *testing.T) {
+	respond := func(status int, code, message string) error {
+		body := fmt.Sprintf(`{"error":{"code":%q,"message":%q}}`, code, message)
+		req, _ := http.NewRequest(http.MethodGet, "https://myvault.vault.azure.net/secrets/db-pass", nil)
+		return runtime.NewResponseError(&http.Response{
+			StatusCode: status,
+			Status:     http.StatusText(status),
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		})
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"disabled", respond(http.StatusForbidden, "Forbidden", "Operation get is not allowed on a disabled secret."), true},
+		{"access policy", respond(http.StatusForbidden, "Forbidden", "The user does not have secrets get permission on key vault 'myvault'."), false},
+		{"other code", respond(http.StatusForbidden, "ForbiddenByFirewall", "Client address is not authorized and caller is not a trusted service."), false},
+		{"not found", respond(http.StatusNotFound, "SecretNotFound", "A secret with (name/id) db-pass was not found in this key vault."), false},
+		{"nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := IsDisabled(tt.err); got != tt.want {
+			t.Errorf("IsDisabled(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package akv
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+)
+
+// Cloud describes an Azure cloud: the DNS suffixes of its key vault and
+// managed HSM endpoints and the authority used to authenticate.
+type Cloud struct {
+	Name          string
+	VaultSuffix   string
+	HSMSuffix     string
+	Configuration cloud.Configuration
+}
+
+var (
+	AzurePublic = Cloud{
+		Name:          "public",
+		VaultSuffix:   DefaultVaultSuffix,
+		HSMSuffix:     ".managedhsm.azure.net",
+		Configuration: cloud.AzurePublic,
+	}
+	AzureChina = Cloud{
+		Name:          "china",
+		VaultSuffix:   ".vault.azure.cn",
+		HSMSuffix:     ".managedhsm.azure.cn",
+		Configuration: cloud.AzureChina,
+	}
+	AzureGovernment = Cloud{
+		Name:          "usgov",
+		VaultSuffix:   ".vault.usgovcloudapi.net",
+		HSMSuffix:     ".managedhsm.usgovcloudapi.net",
+		Configuration: cloud.AzureGovernment,
+	}
+)
+
+// Clouds lists the known clouds.
+var Clouds = []Cloud{AzurePublic, AzureChina, AzureGovernment}
+
+// ParseCloud returns the cloud with the given name. Besides the short
+// names public, china and usgov it accepts the names used by the azure
+// cli, such as AzureCloud, AzureChinaCloud and AzureUSGovernment. An empty
+// name returns AzurePublic.
+func ParseCloud(name string) (Cloud, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "public", "azure", "azurecloud", "azurepublic", "azurepubliccloud":
+		return AzurePublic, nil
+	case "china", "azurechina", "azurechinacloud":
+		return AzureChina, nil
+	case "usgov", "usgovernment", "azureusgovernment", "azureusgovernmentcloud", "azuregovernment":
+		return AzureGovernment, nil
+	}
+
+	return Cloud{}, fmt.Errorf("unknown cloud %q", name)
+}
+
+// CloudFromHost returns the cloud a vault host name belongs to. Managed
+// HSM hosts are not accepted as they do not serve secrets.
+func CloudFromHost(host string) (Cloud, bool) {
+	host = strings.ToLower(host)
+	if h, _, ok := strings.Cut(host, ":"); ok {
+		host = h
+	}
+
+	for _, c := range Clouds {
+		if strings.HasSuffix(host, c.VaultSuffix) {
+			return c, true
+		}
+	}
+
+	return Cloud{}, false
+}
+
+// VaultURL returns the https url of a vault in this cloud given its name,
+// host name or url. Names without a dot get the vault suffix of the cloud
+// appended.
+func (c Cloud) VaultURL(vault string) string {
+	vault = strings.TrimSuffix(vault, "/")
+	if strings.HasPrefix(vault, "https://") || strings.HasPrefix(vault, "http://") {
+		return vault
+	}
+
+	if !strings.Contains(vault, ".") {
+		suffix := c.VaultSuffix
+		if suffix == "" {
+			suffix = DefaultVaultSuffix
+		}
+		vault += suffix
+	}
+
+	return "https://" + vault
+}
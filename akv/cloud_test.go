@@ -0,0 +1,77 @@
+package akv
+
+import "testing"
+
+func TestParseCloud(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		err  bool
+	}{
+		{name: "", want: "public"},
+		{name: "AzureCloud", want: "public"},
+		{name: " china ", want: "china"},
+		{name: "AzureChinaCloud", want: "china"},
+		{name: "usgov", want: "usgov"},
+		{name: "AzureUSGovernment", want: "usgov"},
+		{name: "mars", err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseCloud(tt.name)
+		if tt.err {
+			if err == nil {
+				t.Errorf("ParseCloud(%q) = %s, want an error", tt.name, got.Name)
+			}
+			continue
+		}
+
+		if err != nil || got.Name != tt.want {
+			t.Errorf("ParseCloud(%q) = %s, %v, want %s", tt.name, got.Name, err, tt.want)
+		}
+	}
+}
+
+func TestCloudFromHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+		ok   bool
+	}{
+		{"myvault.vault.azure.net", "public", true},
+		{"MyVault.Vault.Azure.Net:443", "public", true},
+		{"myhsm.managedhsm.azure.net", "", false},
+		{"myvault.vault.azure.cn", "china", true},
+		{"myvault.vault.usgovcloudapi.net", "usgov", true},
+		{"myvault.example.com", "", false},
+		{"vault.azure.net.example.com", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := CloudFromHost(tt.host)
+		if ok != tt.ok || got.Name != tt.want {
+			t.Errorf("CloudFromHost(%q) = %s, %v, want %s, %v", tt.host, got.Name, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCloudVaultURL(t *testing.T) {
+	tests := []struct {
+		cloud Cloud
+		vault string
+		want  string
+	}{
+		{AzurePublic, "myvault", "https://myvault.vault.azure.net"},
+		{AzureChina, "myvault", "https://myvault.vault.azure.cn"},
+		{AzureGovernment, "myvault/", "https://myvault.vault.usgovcloudapi.net"},
+		{AzureGovernment, "myvault.vault.azure.net", "https://myvault.vault.azure.net"},
+		{AzureChina, "https://localhost:8443", "https://localhost:8443"},
+		{Cloud{}, "myvault", "https://myvault.vault.azure.net"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.cloud.VaultURL(tt.vault); got != tt.want {
+			t.Errorf("%s VaultURL(%q) = %q, want %q", tt.cloud.Name, tt.vault, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package akv
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+)
+
+// DeletedSecret holds the properties of a soft deleted secret.
+type DeletedSecret struct {
+	SecretProperties
+	RecoveryID         string
+	DeletedDate        *time.Time
+	ScheduledPurgeDate *time.Time
+}
+
+// DefaultRecoverTimeout is the time Client.Recover waits for a recovery to
+// complete unless told otherwise.
+const DefaultRecoverTimeout = 5 * time.Minute
+
+// RecoverOptions contains optional settings for Client.Recover.
+type RecoverOptions struct {
+	// NoWait returns as soon as the recovery has been accepted instead of
+	// waiting until the secret can be read again.
+	NoWait bool
+	// Timeout bounds the wait, DefaultRecoverTimeout when zero.
+	Timeout time.Duration
+}
+
+// ListDeleted returns the soft deleted secrets whose name matches pattern.
+// The pattern uses filepath.Match syntax and an empty pattern matches
+// every secret.
+func (c *Client) ListDeleted(ctx context.Context, pattern string) ([]DeletedSecret, error) {
+	if pattern != "" {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, newError("list", c.vault, pattern, ErrInvalidPattern)
+		}
+	}
+
+	list := []DeletedSecret{}
+	pager := c.client.NewListDeletedSecretPropertiesPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, newError("list", c.vault, "", err)
+		}
+
+		for _, item := range page.Value {
+			deleted := DeletedSecret{
+				SecretProperties:   newProperties(item.ID, item.ContentType, item.Attributes, item.Tags, item.Managed),
+				DeletedDate:        item.DeletedDate,
+				ScheduledPurgeDate: item.ScheduledPurgeDate,
+			}
+			if item.RecoveryID != nil {
+				deleted.RecoveryID = *item.RecoveryID
+			}
+
+			if pattern != "" {
+				if ok, _ := filepath.Match(pattern, deleted.Name); !ok {
+					continue
+				}
+			}
+
+			list = append(list, deleted)
+		}
+	}
+
+	return list, nil
+}
+
+// Recover restores a soft deleted secret with all of its versions. Unless
+// options.NoWait is set, it waits until the secret exists again. The
+// returned secret has no value when its latest version is disabled.
+func (c *Client) Recover(ctx context.Context, name string, options *RecoverOptions) (*Secret, error) {
+	if name == "" {
+		return nil, newError("recover", c.vault, name, ErrMissingSecretName)
+	}
+
+	if options == nil {
+		options = &RecoverOptions{}
+	}
+
+	resp, err := c.client.RecoverDeletedSecret(ctx, name, nil)
+	if err != nil {
+		return nil, newError("recover", c.vault, name, err)
+	}
+
+	if options.NoWait {
+		return nil, nil
+	}
+
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = DefaultRecoverTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// recovery completes asynchronously and the secret reads as not found
+	// until it is done. Reading a disabled secret is refused instead, which
+	// also shows that it exists.
+	for {
+		secret, err := c.Get(ctx, name, "")
+		if err == nil {
+			return secret, nil
+		}
+
+		if IsDisabled(err) {
+			return newSecret(resp.Secret), nil
+		}
+
+		if !IsNotFound(err) {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, newError("recover", c.vault, name, ctx.Err())
+		case <-time.After(time.Second):
+		}
+	}
+}
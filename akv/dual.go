@@ -0,0 +1,215 @@
+package akv
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"strings"
+	"time"
+)
+
+const (
+	// TagActive marks the slot of a dual secret that consumers use.
+	TagActive = "active"
+	// TagRotateMode is "dual" for the slots of a dual secret.
+	TagRotateMode = "rotate-mode"
+
+	// PrimarySuffix and SecondarySuffix are appended to the name of a dual
+	// secret to get the names of its slots.
+	PrimarySuffix   = "-primary"
+	SecondarySuffix = "-secondary"
+)
+
+// DualSecret is a credential kept in two slots, name-primary and
+// name-secondary, for services that accept two valid values at the same
+// time, such as storage account keys or a pair of database users.
+//
+// Consumers use the slot tagged active=true. Each rotation regenerates the
+// other slot and only then marks it active, so the value consumers read
+// stays valid until the rotation after next.
+type DualSecret struct {
+	Name      string
+	Primary   *Secret
+	Secondary *Secret
+}
+
+// DualName returns the name of the dual secret a slot belongs to, or false
+// when name is not the name of a slot.
+func DualName(name string) (string, bool) {
+	for _, suffix := range []string{PrimarySuffix, SecondarySuffix} {
+		if base, ok := strings.CutSuffix(name, suffix); ok && base != "" {
+			return base, true
+		}
+	}
+
+	return "", false
+}
+
+// Active returns the slot consumers use: the one tagged active=true, the
+// newest one when both are, or the primary one when neither is. It
+// returns nil when no slot exists.
+func (d *DualSecret) Active() *Secret {
+	p, s := d.Primary, d.Secondary
+	switch {
+	case p == nil:
+		return s
+	case s == nil:
+		return p
+	}
+
+	pActive, sActive := p.Tag(TagActive) == "true", s.Tag(TagActive) == "true"
+	if pActive && sActive {
+		if p.Created != nil && s.Created != nil && s.Created.After(*p.Created) {
+			return s
+		}
+		return p
+	}
+
+	if sActive {
+		return s
+	}
+
+	return p
+}
+
+// Standby returns the name of the slot the next rotation regenerates and
+// its current value, which is nil when the slot does not exist yet.
+func (d *DualSecret) Standby() (string, *Secret) {
+	active := d.Active()
+	if active == nil || active == d.Secondary {
+		return d.Name + PrimarySuffix, d.Primary
+	}
+
+	return d.Name + SecondarySuffix, d.Secondary
+}
+
+// GetDual reads both slots of a dual secret. Slots that do not exist are
+// nil; an error is returned when neither exists. A slot without an enabled
+// version, such as one whose first write was refused by a rotation hook,
+// does not exist either and is written again by the next rotation.
+func (c *Client) GetDual(ctx context.Context, name string) (*DualSecret, error) {
+	d := &DualSecret{Name: name}
+	for _, slot := range []struct {
+		suffix string
+		secret **Secret
+	}{{PrimarySuffix, &d.Primary}, {SecondarySuffix, &d.Secondary}} {
+		secret, err := c.Get(ctx, name+slot.suffix, "")
+		if err != nil {
+			if IsNotFound(err) {
+				continue
+			}
+			if IsDisabled(err) {
+				if orphan, verr := c.disabledOnly(ctx, name+slot.suffix); verr == nil && orphan {
+					continue
+				}
+			}
+			return nil, err
+		}
+		*slot.secret = secret
+	}
+
+	if d.Primary == nil && d.Secondary == nil {
+		return nil, newError("get", c.vault, name+PrimarySuffix, ErrSecretNotFound)
+	}
+
+	return d, nil
+}
+
+// RotateDual regenerates the standby slot of a dual secret following the
+// rotation policy of the active slot, or options.Policy, marks it active
+// and then marks the previously active slot inactive. New slots expire two
+// rotation periods from now because they stay in use, as the inactive
+// slot, until the rotation after next.
+//
+// With options.Policy, the slots are created when neither exists. A hook is
+// called before the regenerated slot is enabled, as with Client.Rotate.
+func (c *Client) RotateDual(ctx context.Context, name string, options *RotateOptions) (*Secret, error) {
+	if options == nil {
+		options = &RotateOptions{}
+	}
+
+	d, err := c.GetDual(ctx, name)
+	if err != nil {
+		if !IsNotFound(err) || options.Policy == nil {
+			return nil, err
+		}
+		d = &DualSecret{Name: name}
+	}
+
+	active := d.Active()
+	policy := options.Policy
+	if policy == nil {
+		policy, err = ParseRotationPolicy(active.Tags)
+		if err != nil {
+			return nil, newError("rotate", c.vault, active.Name, err)
+		}
+		if policy == nil {
+			return nil, &Error{Op: "rotate", Vault: c.vault, Name: active.Name, Kind: ErrInvalidPolicy,
+				Err: errors.New("the secret has no " + TagRotateEvery + " tag")}
+		}
+	}
+
+	dual := *policy
+	dual.Dual = true
+
+	generate, err := dual.GenerateOptions(options.Profiles)
+	if err != nil {
+		return nil, newError("rotate", c.vault, name, err)
+	}
+
+	value, err := Generate(generate)
+	if err != nil {
+		return nil, newError("rotate", c.vault, name, err)
+	}
+
+	tags := map[string]string{}
+	setOptions := &SetOptions{}
+	if active != nil {
+		maps.Copy(tags, active.Tags)
+		setOptions.ContentType = active.ContentType
+	}
+	maps.Copy(tags, dual.Tags())
+	tags[TagActive] = "true"
+
+	expires := time.Now().Add(2 * dual.Every)
+	setOptions.Expires = &expires
+	setOptions.Tags = tags
+
+	standbyName, standby := d.Standby()
+	if standby == nil {
+		standby = &Secret{SecretProperties: SecretProperties{Name: standbyName}}
+	}
+
+	next, err := c.replace(ctx, "rotate", standby, value, setOptions, options.Hook)
+	if err != nil {
+		return nil, err
+	}
+
+	if active != nil && active.Name != next.Name {
+		demoted := map[string]string{}
+		maps.Copy(demoted, active.Tags)
+		demoted[TagActive] = "false"
+
+		if _, err := c.Update(ctx, active.Name, active.Version, &UpdateOptions{Tags: demoted}); err != nil {
+			return nil, err
+		}
+	}
+
+	return next, nil
+}
+
+// disabledOnly reports whether no version of the secret is enabled.
+func (c *Client) disabledOnly(ctx context.Context, name string) (bool, error) {
+	versions, err := c.Versions(ctx, name)
+	if err != nil {
+		return false, err
+	}
+
+	for _, v := range versions {
+		if v.Enabled {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
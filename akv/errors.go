@@ -0,0 +1,147 @@
+package akv
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+var (
+	ErrMissingVaultName  = errors.New("vault name is required")
+	ErrMissingSecretName = errors.New("secret name is required")
+	ErrInvalidURL        = errors.New("invalid secret url")
+	ErrInvalidPattern    = errors.New("invalid query pattern")
+	ErrInvalidFilter     = errors.New("invalid filter expression")
+	ErrInvalidPolicy     = errors.New("invalid rotation policy")
+	ErrHookFailed        = errors.New("rotation hook failed")
+	ErrSecretNotFound    = errors.New("secret not found")
+	ErrSecretExpired     = errors.New("secret has expired")
+	ErrGenerateFailed    = errors.New("failed to generate secret")
+	ErrClientFailed      = errors.New("failed to create key vault client")
+)
+
+// Error describes a failed key vault operation. Kind is one of the Err*
+// sentinel values when the failure could be classified, and Err is the
+// underlying cause, usually an *azcore.ResponseError.
+type Error struct {
+	Op    string
+	Vault string
+	Name  string
+	Kind  error
+	Err   error
+}
+
+func (e *Error) Error() string {
+	msg := e.Op
+	if e.Name != "" {
+		msg += " " + e.Name
+	}
+	if e.Vault != "" {
+		msg += " (" + e.Vault + ")"
+	}
+
+	if e.Kind != nil {
+		msg += ": " + e.Kind.Error()
+	}
+
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+
+	return msg
+}
+
+func (e *Error) Unwrap() []error {
+	errs := []error{}
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// IsNotFound reports whether err is a key vault response for a secret
+// that does not exist.
+func IsNotFound(err error) bool {
+	if errors.Is(err, ErrSecretNotFound) {
+		return true
+	}
+
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.ErrorCode == "SecretNotFound" || respErr.StatusCode == http.StatusNotFound
+	}
+
+	return false
+}
+
+// IsConflict reports whether err is a key vault response for a secret that
+// already exists, for example when restoring a backup, or that is deleted
+// but not purged yet.
+func IsConflict(err error) bool {
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode == http.StatusConflict
+	}
+
+	return false
+}
+
+// IsForbidden reports whether err is a key vault response refusing the
+// operation, for example reading the value of a disabled secret.
+func IsForbidden(err error) bool {
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode == http.StatusForbidden
+	}
+
+	return false
+}
+
+// IsDisabled reports whether err is a key vault response refusing to read
+// the value of a disabled secret. Other forbidden responses, such as a
+// missing access policy, are not.
+func IsDisabled(err error) bool {
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode == http.StatusForbidden && respErr.ErrorCode == "Forbidden" &&
+			strings.Contains(respErr.Error(), "disabled secret")
+	}
+
+	return false
+}
+
+// newError wraps err for the operation op. Errors that are already one of
+// the sentinel values become the Kind of the returned error.
+func newError(op, vault, name string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	e := &Error{Op: op, Vault: vault, Name: name}
+	switch {
+	case isKind(err):
+		e.Kind = err
+	case IsNotFound(err):
+		e.Kind = ErrSecretNotFound
+		e.Err = err
+	default:
+		e.Err = err
+	}
+
+	return e
+}
+
+func isKind(err error) bool {
+	switch err {
+	case ErrMissingVaultName, ErrMissingSecretName, ErrInvalidURL, ErrInvalidPattern,
+		ErrInvalidFilter, ErrInvalidPolicy, ErrHookFailed, ErrSecretNotFound, ErrSecretExpired, ErrGenerateFailed, ErrClientFailed:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,36 @@
+package akv
+
+import "time"
+
+// ExpiryStatus classifies a secret by its expiry and activation dates.
+type ExpiryStatus string
+
+const (
+	ExpiryOK        ExpiryStatus = "ok"
+	ExpiryExpired   ExpiryStatus = "expired"
+	ExpiryExpiring  ExpiryStatus = "expiring"
+	ExpiryNotActive ExpiryStatus = "not-active"
+	ExpiryMissing   ExpiryStatus = "no-expiry"
+)
+
+// ExpiryStatuses lists every status from the most to the least urgent.
+var ExpiryStatuses = []ExpiryStatus{ExpiryExpired, ExpiryExpiring, ExpiryNotActive, ExpiryMissing, ExpiryOK}
+
+// Expiry returns the status of the secret at now. A secret is expiring
+// when it expires within the given duration and not active when its
+// NotBefore date is after now. When several statuses apply the most urgent
+// one is returned.
+func (p *SecretProperties) Expiry(now time.Time, within time.Duration) ExpiryStatus {
+	switch {
+	case p.IsExpired(now):
+		return ExpiryExpired
+	case p.Expires != nil && p.Expires.Before(now.Add(within)):
+		return ExpiryExpiring
+	case p.NotBefore != nil && now.Before(*p.NotBefore):
+		return ExpiryNotActive
+	case p.Expires == nil:
+		return ExpiryMissing
+	}
+
+	return ExpiryOK
+}
@@ -0,0 +1,535 @@
+package akv
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mashiike/longduration"
+)
+
+// Filter is a compiled filter expression that is evaluated against the
+// properties of a secret. See ParseFilter for the syntax.
+type Filter struct {
+	expr string
+	root filterNode
+}
+
+// ParseFilter compiles a filter expression such as
+//
+//	tags.env == "prod" && expires < now+30d && enabled
+//
+// Fields are name, version, content_type and tags.<key> or tags["<key>"]
+// (strings), enabled and managed (booleans) and expires, not_before,
+// created and updated (times). Literals are double quoted strings, true,
+// false, null and now, optionally followed by + or - and a duration such
+// as 30d or 12h. Strings compared to times are parsed as RFC3339 or
+// 2006-01-02.
+//
+// The operators are ==, !=, <, <=, >, >=, =~ (match against a quoted
+// regular expression), !, && and ||, with parentheses for grouping. A
+// field on its own is true when it is a true boolean, a non-empty string
+// or a set time. Missing times only compare equal to null.
+func ParseFilter(expr string) (*Filter, error) {
+	tokens, err := lexFilter(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+	}
+
+	p := &filterParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	if err == nil && root.kind != kindBool {
+		root = truthy(root)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+	}
+
+	return &Filter{expr: expr, root: root}, nil
+}
+
+// String returns the source of the filter.
+func (f *Filter) String() string {
+	return f.expr
+}
+
+// Match reports whether the properties satisfy the filter. now is the
+// time used for the now literal.
+func (f *Filter) Match(props *SecretProperties, now time.Time) bool {
+	return f.root.eval(props, now).b
+}
+
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindBool
+	kindTime
+	kindNull
+)
+
+func (k valueKind) String() string {
+	switch k {
+	case kindString:
+		return "string"
+	case kindBool:
+		return "bool"
+	case kindTime:
+		return "time"
+	default:
+		return "null"
+	}
+}
+
+type filterValue struct {
+	s string
+	b bool
+	t *time.Time
+}
+
+type filterNode struct {
+	kind valueKind
+	// literal is set for string literals, whose value is known when the
+	// expression is parsed.
+	literal bool
+	eval    func(props *SecretProperties, now time.Time) filterValue
+}
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokString
+	tokDuration
+	tokOp
+)
+
+type filterToken struct {
+	kind tokenKind
+	text string
+}
+
+func lexFilter(expr string) ([]filterToken, error) {
+	tokens := []filterToken{}
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != '"'; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+				b.WriteRune(runes[j])
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			tokens = append(tokens, filterToken{tokString, b.String()})
+			i = j + 1
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || unicode.IsLetter(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, filterToken{tokDuration, string(runes[i:j])})
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_' || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, filterToken{tokIdent, string(runes[i:j])})
+			i = j
+		default:
+			op := ""
+			for _, candidate := range []string{"==", "!=", "<=", ">=", "=~", "&&", "||", "<", ">", "!", "(", ")", "[", "]", "+", "-"} {
+				if strings.HasPrefix(string(runes[i:]), candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at %d", r, i)
+			}
+			tokens = append(tokens, filterToken{tokOp, op})
+			i += len([]rune(op))
+		}
+	}
+
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) peek() (filterToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return filterToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *filterParser) acceptOp(op string) bool {
+	if t, ok := p.peek(); ok && t.kind == tokOp && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return left, err
+	}
+
+	for p.acceptOp("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return right, err
+		}
+		l, r := truthy(left), truthy(right)
+		left = boolNode(func(props *SecretProperties, now time.Time) bool {
+			return l.eval(props, now).b || r.eval(props, now).b
+		})
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return left, err
+	}
+
+	for p.acceptOp("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return right, err
+		}
+		l, r := truthy(left), truthy(right)
+		left = boolNode(func(props *SecretProperties, now time.Time) bool {
+			return l.eval(props, now).b && r.eval(props, now).b
+		})
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterNode, error) {
+	if p.acceptOp("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return operand, err
+		}
+		n := truthy(operand)
+		return boolNode(func(props *SecretProperties, now time.Time) bool {
+			return !n.eval(props, now).b
+		}), nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (filterNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return left, err
+	}
+
+	t, ok := p.peek()
+	if !ok || t.kind != tokOp {
+		return left, nil
+	}
+
+	switch t.text {
+	case "==", "!=", "<", "<=", ">", ">=", "=~":
+		p.pos++
+	default:
+		return left, nil
+	}
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return right, err
+	}
+
+	return compare(t.text, left, right)
+}
+
+func (p *filterParser) parseOperand() (filterNode, error) {
+	t, ok := p.peek()
+	if !ok {
+		return filterNode{}, fmt.Errorf("unexpected end of expression")
+	}
+
+	if t.kind == tokOp && t.text == "(" {
+		p.pos++
+		n, err := p.parseOr()
+		if err != nil {
+			return n, err
+		}
+		if !p.acceptOp(")") {
+			return n, fmt.Errorf("missing )")
+		}
+		return n, nil
+	}
+
+	p.pos++
+	switch t.kind {
+	case tokString:
+		s := t.text
+		return filterNode{kind: kindString, literal: true, eval: func(*SecretProperties, time.Time) filterValue {
+			return filterValue{s: s}
+		}}, nil
+	case tokIdent:
+		return p.parseIdent(t.text)
+	default:
+		return filterNode{}, fmt.Errorf("unexpected %q", t.text)
+	}
+}
+
+func (p *filterParser) parseIdent(name string) (filterNode, error) {
+	switch strings.ToLower(name) {
+	case "true", "false":
+		b := strings.EqualFold(name, "true")
+		return boolNode(func(*SecretProperties, time.Time) bool { return b }), nil
+	case "null", "nil":
+		return filterNode{kind: kindNull, eval: func(*SecretProperties, time.Time) filterValue {
+			return filterValue{}
+		}}, nil
+	case "now":
+		offset := time.Duration(0)
+		if t, ok := p.peek(); ok && t.kind == tokOp && (t.text == "+" || t.text == "-") {
+			p.pos++
+			d, ok := p.peek()
+			if !ok || d.kind != tokDuration {
+				return filterNode{}, fmt.Errorf("expected a duration after now%s", t.text)
+			}
+			p.pos++
+
+			dur, err := longduration.ParseDuration(d.text)
+			if err != nil {
+				return filterNode{}, fmt.Errorf("invalid duration %q", d.text)
+			}
+
+			offset = dur
+			if t.text == "-" {
+				offset = -dur
+			}
+		}
+		return timeNode(func(_ *SecretProperties, now time.Time) *time.Time {
+			t := now.Add(offset)
+			return &t
+		}), nil
+	case "name":
+		return stringNode(func(p *SecretProperties) string { return p.Name }), nil
+	case "version":
+		return stringNode(func(p *SecretProperties) string { return p.Version }), nil
+	case "content_type", "contenttype":
+		return stringNode(func(p *SecretProperties) string { return p.ContentType }), nil
+	case "enabled":
+		return boolNode(func(p *SecretProperties, _ time.Time) bool { return p.Enabled }), nil
+	case "managed":
+		return boolNode(func(p *SecretProperties, _ time.Time) bool { return p.Managed }), nil
+	case "expires":
+		return timeNode(func(p *SecretProperties, _ time.Time) *time.Time { return p.Expires }), nil
+	case "not_before", "notbefore":
+		return timeNode(func(p *SecretProperties, _ time.Time) *time.Time { return p.NotBefore }), nil
+	case "created":
+		return timeNode(func(p *SecretProperties, _ time.Time) *time.Time { return p.Created }), nil
+	case "updated":
+		return timeNode(func(p *SecretProperties, _ time.Time) *time.Time { return p.Updated }), nil
+	case "tags":
+		// tags["key"] for keys that are not identifiers.
+		if !p.acceptOp("[") {
+			return filterNode{}, fmt.Errorf("expected tags.<key> or tags[\"<key>\"]")
+		}
+		t, ok := p.peek()
+		if !ok || t.kind != tokString {
+			return filterNode{}, fmt.Errorf("expected a quoted tag name")
+		}
+		p.pos++
+		if !p.acceptOp("]") {
+			return filterNode{}, fmt.Errorf("missing ]")
+		}
+		key := t.text
+		return stringNode(func(p *SecretProperties) string { return p.Tag(key) }), nil
+	}
+
+	if key, ok := strings.CutPrefix(name, "tags."); ok && key != "" {
+		return stringNode(func(p *SecretProperties) string { return p.Tag(key) }), nil
+	}
+
+	return filterNode{}, fmt.Errorf("unknown field %q", name)
+}
+
+func boolNode(fn func(*SecretProperties, time.Time) bool) filterNode {
+	return filterNode{kind: kindBool, eval: func(p *SecretProperties, now time.Time) filterValue {
+		return filterValue{b: fn(p, now)}
+	}}
+}
+
+func stringNode(fn func(*SecretProperties) string) filterNode {
+	return filterNode{kind: kindString, eval: func(p *SecretProperties, _ time.Time) filterValue {
+		return filterValue{s: fn(p)}
+	}}
+}
+
+func timeNode(fn func(*SecretProperties, time.Time) *time.Time) filterNode {
+	return filterNode{kind: kindTime, eval: func(p *SecretProperties, now time.Time) filterValue {
+		return filterValue{t: fn(p, now)}
+	}}
+}
+
+// truthy converts a node to a boolean: true booleans, non-empty strings
+// and set times are true.
+func truthy(n filterNode) filterNode {
+	switch n.kind {
+	case kindBool:
+		return n
+	case kindString:
+		return boolNode(func(p *SecretProperties, now time.Time) bool { return n.eval(p, now).s != "" })
+	case kindTime:
+		return boolNode(func(p *SecretProperties, now time.Time) bool { return n.eval(p, now).t != nil })
+	default:
+		return boolNode(func(*SecretProperties, time.Time) bool { return false })
+	}
+}
+
+func compare(op string, left, right filterNode) (filterNode, error) {
+	// string literals compared to times are parsed as times.
+	if left.kind == kindTime && right.kind == kindString {
+		n, err := literalTime(right)
+		if err != nil {
+			return n, err
+		}
+		right = n
+	} else if left.kind == kindString && right.kind == kindTime {
+		n, err := literalTime(left)
+		if err != nil {
+			return n, err
+		}
+		left = n
+	}
+
+	if op == "=~" {
+		if left.kind != kindString || right.kind != kindString {
+			return filterNode{}, fmt.Errorf("=~ needs strings, got %s and %s", left.kind, right.kind)
+		}
+		if !right.literal {
+			return filterNode{}, fmt.Errorf("=~ needs a quoted string pattern on the right")
+		}
+
+		pattern := right.eval(&SecretProperties{}, time.Time{}).s
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return filterNode{}, fmt.Errorf("invalid regular expression %q: %v", pattern, err)
+		}
+
+		return boolNode(func(p *SecretProperties, now time.Time) bool {
+			return re.MatchString(left.eval(p, now).s)
+		}), nil
+	}
+
+	if left.kind == kindNull || right.kind == kindNull {
+		other := left
+		if left.kind == kindNull {
+			other = right
+		}
+		if op != "==" && op != "!=" {
+			return filterNode{}, fmt.Errorf("%s cannot compare with null", op)
+		}
+
+		isNull := func(p *SecretProperties, now time.Time) bool {
+			v := other.eval(p, now)
+			switch other.kind {
+			case kindTime:
+				return v.t == nil
+			case kindString:
+				return v.s == ""
+			case kindNull:
+				return true
+			}
+			return false
+		}
+
+		return boolNode(func(p *SecretProperties, now time.Time) bool {
+			return isNull(p, now) == (op == "==")
+		}), nil
+	}
+
+	if left.kind != right.kind {
+		return filterNode{}, fmt.Errorf("cannot compare %s with %s", left.kind, right.kind)
+	}
+
+	switch left.kind {
+	case kindBool:
+		if op != "==" && op != "!=" {
+			return filterNode{}, fmt.Errorf("%s cannot compare booleans", op)
+		}
+		return boolNode(func(p *SecretProperties, now time.Time) bool {
+			return (left.eval(p, now).b == right.eval(p, now).b) == (op == "==")
+		}), nil
+	case kindString:
+		return boolNode(func(p *SecretProperties, now time.Time) bool {
+			return ordered(op, strings.Compare(left.eval(p, now).s, right.eval(p, now).s))
+		}), nil
+	default:
+		return boolNode(func(p *SecretProperties, now time.Time) bool {
+			a, b := left.eval(p, now).t, right.eval(p, now).t
+			if a == nil || b == nil {
+				// missing times only compare equal to null.
+				return op == "!=" && (a == nil) != (b == nil)
+			}
+			return ordered(op, a.Compare(*b))
+		}), nil
+	}
+}
+
+// literalTime converts a string literal node to a time node.
+func literalTime(n filterNode) (filterNode, error) {
+	if !n.literal {
+		return filterNode{}, fmt.Errorf("times can only be compared to times, null or quoted dates")
+	}
+
+	s := n.eval(&SecretProperties{}, time.Time{}).s
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return timeNode(func(*SecretProperties, time.Time) *time.Time { return &t }), nil
+		}
+	}
+
+	return filterNode{}, fmt.Errorf("invalid time %q, use RFC3339 or 2006-01-02", s)
+}
+
+func ordered(op string, c int) bool {
+	switch op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package akv
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseFilter(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	soon := now.Add(10 * 24 * time.Hour)
+	created := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	props := &SecretProperties{
+		Name:        "db-pass",
+		Version:     "abc123",
+		ContentType: "text/plain",
+		Enabled:     true,
+		Expires:     &soon,
+		Created:     &created,
+		Tags:        map[string]string{"env": "prod", "team-name": "api"},
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`enabled`, true},
+		{`!enabled`, false},
+		{`managed`, false},
+		{`name == "db-pass"`, true},
+		{`name != "db-pass"`, false},
+		{`tags.env == "prod"`, true},
+		{`tags["team-name"] == "api"`, true},
+		{`tags.missing`, false},
+		{`tags.env`, true},
+		{`content_type == "text/plain"`, true},
+		{`name =~ "^db-"`, true},
+		{`name =~ "^api-"`, false},
+		{`expires < now+30d`, true},
+		{`expires < now+7d`, false},
+		{`expires >= now`, true},
+		{`not_before == null`, true},
+		{`expires != null`, true},
+		{`not_before < now`, false},
+		{`created < "2025-02-01"`, true},
+		{`created > "2025-01-15T12:00:00Z"`, false},
+		{`updated`, false},
+		{`tags.env == "prod" && expires < now+30d && enabled`, true},
+		{`tags.env == "dev" || name == "db-pass"`, true},
+		{`!(tags.env == "dev" || name == "api-key")`, true},
+		{`tags.env == "dev" || name == "api-key" && enabled`, false},
+	}
+
+	for _, tt := range tests {
+		f, err := ParseFilter(tt.expr)
+		if err != nil {
+			t.Errorf("ParseFilter(%q) error = %v", tt.expr, err)
+			continue
+		}
+		if got := f.Match(props, now); got != tt.want {
+			t.Errorf("ParseFilter(%q).Match() = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	tests := []string{
+		``,
+		`name ==`,
+		`name == "db-pass" &&`,
+		`(enabled`,
+		`unknown == "x"`,
+		`tags[env] == "prod"`,
+		`name =~ "("`,
+		// the pattern of =~ must be a quoted literal.
+		`name =~ version`,
+		`name =~ tags.pattern`,
+		// times only compare to times, null and quoted dates.
+		`expires < name`,
+		`expires < "next week"`,
+		`name < 3`,
+		`now + 30`,
+		`name == "unterminated`,
+	}
+
+	for _, expr := range tests {
+		if _, err := ParseFilter(expr); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("ParseFilter(%q) error = %v, want ErrInvalidFilter", expr, err)
+		}
+	}
+}
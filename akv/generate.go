@@ -0,0 +1,286 @@
+package akv
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+
+	"github.com/hyprxlabs/go/secrets"
+)
+
+// DefaultSpecial is the set of special characters used when generating
+// secrets unless another set is given.
+const DefaultSpecial = "@#`~_-[]|+="
+
+// GenerateOptions controls how new secret values are generated.
+type GenerateOptions struct {
+	// Size is the length of the generated value.
+	Size int16
+	// Upper, Lower and Digits require at least one character of that class.
+	Upper  bool
+	Lower  bool
+	Digits bool
+	// NoSpecial disables special characters.
+	NoSpecial bool
+	// Special is the set of special characters to use.
+	Special string
+	// Chars, when set, is the only set of characters used and disables
+	// the character class requirements.
+	Chars string
+	// MinUpper, MinLower, MinDigits and MinSpecial are the minimum number
+	// of characters of each class. A minimum also enables its class.
+	MinUpper   int
+	MinLower   int
+	MinDigits  int
+	MinSpecial int
+	// Exclude lists characters that are never used, such as
+	// AmbiguousChars.
+	Exclude string
+}
+
+// AmbiguousChars are characters that are easily confused with each other
+// when a value is read or typed.
+const AmbiguousChars = "Il1|O0o"
+
+// NistGenerateOptions returns options that require upper and lower case
+// letters, digits and special characters.
+func NistGenerateOptions(size int16) GenerateOptions {
+	return GenerateOptions{
+		Size:    size,
+		Upper:   true,
+		Lower:   true,
+		Digits:  true,
+		Special: DefaultSpecial,
+	}
+}
+
+// Generate creates a new random secret value.
+func Generate(options GenerateOptions) (string, error) {
+	size := options.Size
+	if size <= 0 {
+		size = 16
+	}
+
+	if options.MinUpper > 0 || options.MinLower > 0 || options.MinDigits > 0 || options.MinSpecial > 0 || options.Exclude != "" {
+		return generateCounted(size, options)
+	}
+
+	if len(options.Chars) > 0 {
+		value, err := secrets.Generate(size, secrets.WithChars(options.Chars), secrets.WithValidator(func(s []rune) error {
+			return nil
+		}))
+		if err != nil {
+			return "", errors.Join(ErrGenerateFailed, err)
+		}
+		return value, nil
+	}
+
+	var symbolOpt secrets.SetOption
+	if !options.NoSpecial {
+		special := options.Special
+		if special == "" {
+			special = DefaultSpecial
+		}
+		symbolOpt = secrets.WithSymbols(special)
+	} else {
+		symbolOpt = secrets.WithNoSymbols()
+	}
+
+	validator := secrets.WithValidator(func(s []rune) error {
+		hasUpper := false
+		hasLower := false
+		hasDigits := false
+		hasSpecial := false
+
+		for _, r := range s {
+			if unicode.IsUpper(r) {
+				hasUpper = true
+			} else if unicode.IsLower(r) {
+				hasLower = true
+			} else if unicode.IsDigit(r) {
+				hasDigits = true
+			} else {
+				hasSpecial = true
+			}
+		}
+
+		if options.Upper && !hasUpper {
+			return errors.New("secret must contain at least one uppercase letter")
+		}
+
+		if options.Lower && !hasLower {
+			return errors.New("secret must contain at least one lowercase letter")
+		}
+
+		if options.Digits && !hasDigits {
+			return errors.New("secret must contain at least one digit")
+		}
+
+		if !options.NoSpecial && !hasSpecial {
+			return errors.New("secret must contain at least one special character")
+		}
+
+		if len(s) < 1 {
+			return errors.New("secret must be at least 1 character long")
+		}
+
+		return nil
+	})
+
+	value, err := secrets.Generate(size, symbolOpt, validator, secrets.WithUpper(options.Upper), secrets.WithLower(options.Lower), secrets.WithDigits(options.Digits))
+	if err != nil {
+		return "", errors.Join(ErrGenerateFailed, err)
+	}
+
+	return value, nil
+}
+
+// generateCounted generates a value from an explicit character set so that
+// excluded characters are left out and the minimum counts are met.
+func generateCounted(size int16, options GenerateOptions) (string, error) {
+	minUpper, minLower, minDigits, minSpecial := options.MinUpper, options.MinLower, options.MinDigits, options.MinSpecial
+
+	chars := options.Chars
+	if chars == "" {
+		upper := options.Upper || minUpper > 0
+		lower := options.Lower || minLower > 0
+		digits := options.Digits || minDigits > 0
+		if !upper && !lower && !digits {
+			upper, lower, digits = true, true, true
+		}
+
+		if upper {
+			chars += "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+			minUpper = max(minUpper, 1)
+		}
+		if lower {
+			chars += "abcdefghijklmnopqrstuvwxyz"
+			minLower = max(minLower, 1)
+		}
+		if digits {
+			chars += "0123456789"
+			minDigits = max(minDigits, 1)
+		}
+		if !options.NoSpecial || minSpecial > 0 {
+			special := options.Special
+			if special == "" {
+				special = DefaultSpecial
+			}
+			chars += special
+			minSpecial = max(minSpecial, 1)
+		}
+	}
+
+	chars = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(options.Exclude, r) {
+			return -1
+		}
+		return r
+	}, chars)
+
+	if minUpper+minLower+minDigits+minSpecial > int(size) {
+		return "", errors.Join(ErrGenerateFailed, fmt.Errorf("the minimum counts add up to more than the size of %d", size))
+	}
+
+	available := countClasses([]rune(chars))
+	for _, class := range []struct {
+		name      string
+		min, have int
+	}{
+		{"uppercase letters", minUpper, available.upper},
+		{"lowercase letters", minLower, available.lower},
+		{"digits", minDigits, available.digits},
+		{"special characters", minSpecial, available.special},
+	} {
+		if class.min > 0 && class.have == 0 {
+			return "", errors.Join(ErrGenerateFailed, fmt.Errorf("no %s are left after excluding %q", class.name, options.Exclude))
+		}
+	}
+
+	if chars == "" {
+		return "", errors.Join(ErrGenerateFailed, errors.New("no characters are left to generate from"))
+	}
+
+	// the required characters of each class are drawn first and the rest
+	// from every character, then the value is shuffled, so that large
+	// minimums do not depend on retries.
+	runes := []rune(chars)
+	value := make([]rune, 0, size)
+	for _, class := range []struct {
+		min int
+		in  func(rune) bool
+	}{
+		{minUpper, unicode.IsUpper},
+		{minLower, unicode.IsLower},
+		{minDigits, unicode.IsDigit},
+		{minSpecial, isSpecial},
+	} {
+		pool := []rune{}
+		for _, r := range runes {
+			if class.in(r) {
+				pool = append(pool, r)
+			}
+		}
+		for range class.min {
+			r, err := randomRune(pool)
+			if err != nil {
+				return "", errors.Join(ErrGenerateFailed, err)
+			}
+			value = append(value, r)
+		}
+	}
+
+	for len(value) < int(size) {
+		r, err := randomRune(runes)
+		if err != nil {
+			return "", errors.Join(ErrGenerateFailed, err)
+		}
+		value = append(value, r)
+	}
+
+	for i := len(value) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", errors.Join(ErrGenerateFailed, err)
+		}
+		value[i], value[j.Int64()] = value[j.Int64()], value[i]
+	}
+
+	return string(value), nil
+}
+
+func randomRune(pool []rune) (rune, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(pool))))
+	if err != nil {
+		return 0, err
+	}
+	return pool[n.Int64()], nil
+}
+
+func isSpecial(r rune) bool {
+	return !unicode.IsUpper(r) && !unicode.IsLower(r) && !unicode.IsDigit(r)
+}
+
+type classCounts struct {
+	upper, lower, digits, special int
+}
+
+func countClasses(s []rune) classCounts {
+	counts := classCounts{}
+	for _, r := range s {
+		switch {
+		case unicode.IsUpper(r):
+			counts.upper++
+		case unicode.IsLower(r):
+			counts.lower++
+		case unicode.IsDigit(r):
+			counts.digits++
+		default:
+			counts.special++
+		}
+	}
+	return counts
+}
@@ -0,0 +1,100 @@
+package akv
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestGenerateCounted(t *testing.T) {
+	tests := []struct {
+		name    string
+		options GenerateOptions
+		want    classCounts
+	}{
+		{"defaults", GenerateOptions{Size: 16}, classCounts{upper: 1, lower: 1, digits: 1, special: 1}},
+		{"minimums", GenerateOptions{Size: 12, MinUpper: 3, MinLower: 2, MinDigits: 4, MinSpecial: 1}, classCounts{upper: 3, lower: 2, digits: 4, special: 1}},
+		{"minimums fill the value", GenerateOptions{Size: 8, MinUpper: 4, MinDigits: 4, NoSpecial: true}, classCounts{upper: 4, digits: 4}},
+		{"digits only", GenerateOptions{Size: 10, MinDigits: 2, NoSpecial: true}, classCounts{digits: 2}},
+		{"special set", GenerateOptions{Size: 20, MinSpecial: 5, Special: "!?"}, classCounts{upper: 1, lower: 1, digits: 1, special: 5}},
+		{"sql-server", Profiles["sql-server"], classCounts{upper: 2, lower: 2, digits: 2, special: 2}},
+		{"url-safe", Profiles["url-safe"], classCounts{upper: 1, lower: 1, digits: 1}},
+	}
+
+	for _, tt := range tests {
+		for range 50 {
+			value, err := generateCounted(tt.options.Size, tt.options)
+			if err != nil {
+				t.Fatalf("%s: generateCounted() error = %v", tt.name, err)
+			}
+			if n := utf8.RuneCountInString(value); n != int(tt.options.Size) {
+				t.Fatalf("%s: generateCounted() = %q has %d characters, want %d", tt.name, value, n, tt.options.Size)
+			}
+
+			got := countClasses([]rune(value))
+			if got.upper < tt.want.upper || got.lower < tt.want.lower || got.digits < tt.want.digits || got.special < tt.want.special {
+				t.Fatalf("%s: generateCounted() = %q has %+v, want at least %+v", tt.name, value, got, tt.want)
+			}
+
+			if tt.options.NoSpecial && got.special > 0 {
+				t.Fatalf("%s: generateCounted() = %q has special characters", tt.name, value)
+			}
+			if tt.options.Special != "" && strings.ContainsFunc(value, func(r rune) bool {
+				return isSpecial(r) && !strings.ContainsRune(tt.options.Special, r)
+			}) {
+				t.Fatalf("%s: generateCounted() = %q has special characters outside of %q", tt.name, value, tt.options.Special)
+			}
+			if tt.options.Chars != "" && strings.ContainsFunc(value, func(r rune) bool { return !strings.ContainsRune(tt.options.Chars, r) }) {
+				t.Fatalf("%s: generateCounted() = %q has characters outside of %q", tt.name, value, tt.options.Chars)
+			}
+			if strings.ContainsAny(value, tt.options.Exclude) {
+				t.Fatalf("%s: generateCounted() = %q has excluded characters %q", tt.name, value, tt.options.Exclude)
+			}
+		}
+	}
+}
+
+func TestGenerateCountedExclude(t *testing.T) {
+	options := GenerateOptions{Size: 64, Chars: "abc", Exclude: "b"}
+	for range 20 {
+		value, err := generateCounted(options.Size, options)
+		if err != nil {
+			t.Fatalf("generateCounted() error = %v", err)
+		}
+		if strings.Contains(value, "b") || strings.Trim(value, "ac") != "" {
+			t.Fatalf("generateCounted() = %q, want only a and c", value)
+		}
+	}
+
+	options = GenerateOptions{Size: 32, Exclude: AmbiguousChars, NoSpecial: true}
+	for range 20 {
+		value, err := generateCounted(options.Size, options)
+		if err != nil {
+			t.Fatalf("generateCounted() error = %v", err)
+		}
+		if strings.ContainsAny(value, AmbiguousChars) {
+			t.Fatalf("generateCounted() = %q has ambiguous characters", value)
+		}
+	}
+}
+
+func TestGenerateCountedErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		options GenerateOptions
+		want    string
+	}{
+		{"minimums over size", GenerateOptions{Size: 4, MinUpper: 2, MinDigits: 3}, "add up to more than the size of 4"},
+		{"class excluded", GenerateOptions{Size: 8, MinDigits: 1, NoSpecial: true, Exclude: "0123456789"}, "no digits are left"},
+		{"special excluded", GenerateOptions{Size: 8, MinSpecial: 1, Special: "!", Exclude: "!"}, "no special characters are left"},
+		{"chars excluded", GenerateOptions{Size: 8, Chars: "ab", Exclude: "ab"}, "no characters are left"},
+	}
+
+	for _, tt := range tests {
+		_, err := generateCounted(tt.options.Size, tt.options)
+		if !errors.Is(err, ErrGenerateFailed) || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: generateCounted() error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
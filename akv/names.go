@@ -0,0 +1,62 @@
+package akv
+
+import (
+	"strings"
+)
+
+// VariableName converts a secret name to an environment variable name by
+// upper casing it and replacing every character that is not a letter or a
+// digit with an underscore, for example db-password becomes DB_PASSWORD.
+// Names that start with a digit get a leading underscore.
+func VariableName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z':
+			b.WriteRune(r - 'a' + 'A')
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+
+	s := b.String()
+	if s != "" && s[0] >= '0' && s[0] <= '9' {
+		s = "_" + s
+	}
+
+	return s
+}
+
+// SecretName converts a variable or key name to a secret name that key
+// vault accepts by lower casing it, replacing runs of characters other
+// than letters, digits and dashes with a single dash and trimming dashes
+// from both ends, for example DB_PASSWORD becomes db-password. The result
+// is at most 127 characters and is empty when name has no valid character.
+func SecretName(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range name {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			b.WriteRune(r - 'A' + 'a')
+			dash = false
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+			dash = false
+		default:
+			if !dash {
+				b.WriteRune('-')
+				dash = true
+			}
+		}
+	}
+
+	s := strings.Trim(b.String(), "-")
+	if len(s) > 127 {
+		s = strings.TrimRight(s[:127], "-")
+	}
+
+	return s
+}
@@ -0,0 +1,152 @@
+package akv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const urlSafeChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+
+// Profiles are the built-in generator profiles.
+var Profiles = map[string]GenerateOptions{
+	// nist requires every character class.
+	"nist": NistGenerateOptions(16),
+	// sql-server meets the SQL Server password policy and leaves out
+	// quotes, semicolons and braces that break connection strings.
+	"sql-server": {
+		Size:       32,
+		MinUpper:   2,
+		MinLower:   2,
+		MinDigits:  2,
+		MinSpecial: 2,
+		Special:    "!#$%*+-=?^_",
+		Exclude:    AmbiguousChars,
+	},
+	// url-safe only uses characters that need no escaping in urls.
+	"url-safe": {
+		Size:      32,
+		Chars:     urlSafeChars,
+		MinUpper:  1,
+		MinLower:  1,
+		MinDigits: 1,
+	},
+	// hex64 is 64 hex digits, e.g. a 256 bit key.
+	"hex64": {
+		Size:  64,
+		Chars: "0123456789abcdef",
+	},
+}
+
+// ParseGenerateOptions parses generator options written as space separated
+// key=value pairs, the format profiles are stored in:
+//
+//	size=24 min-upper=2 min-digits=2 special=!#$% exclude=Il1O0
+//
+// The keys are size, chars, special, exclude, min-upper, min-lower,
+// min-digits and min-special, and the flags upper, lower, digits,
+// no-special and no-ambiguous, which may be given without a value.
+// no-ambiguous adds AmbiguousChars to exclude. Values cannot contain
+// spaces.
+func ParseGenerateOptions(spec string) (GenerateOptions, error) {
+	options := GenerateOptions{}
+	for _, field := range strings.Fields(spec) {
+		key, value, hasValue := strings.Cut(field, "=")
+
+		flag := func() (bool, error) {
+			if !hasValue {
+				return true, nil
+			}
+			return strconv.ParseBool(value)
+		}
+
+		number := func() (int, error) {
+			n, err := strconv.Atoi(value)
+			if err == nil && n < 0 {
+				err = fmt.Errorf("must not be negative")
+			}
+			return n, err
+		}
+
+		var err error
+		switch strings.ToLower(key) {
+		case "size", "length":
+			var n int
+			n, err = number()
+			if err == nil && n > 32767 {
+				err = fmt.Errorf("must be at most 32767")
+			}
+			options.Size = int16(n)
+		case "chars":
+			options.Chars = value
+		case "special":
+			options.Special = value
+		case "exclude":
+			options.Exclude += value
+		case "min-upper":
+			options.MinUpper, err = number()
+		case "min-lower":
+			options.MinLower, err = number()
+		case "min-digits":
+			options.MinDigits, err = number()
+		case "min-special":
+			options.MinSpecial, err = number()
+		case "upper":
+			options.Upper, err = flag()
+		case "lower":
+			options.Lower, err = flag()
+		case "digits":
+			options.Digits, err = flag()
+		case "no-special":
+			options.NoSpecial, err = flag()
+		case "no-ambiguous":
+			var b bool
+			if b, err = flag(); b {
+				options.Exclude += AmbiguousChars
+			}
+		default:
+			err = fmt.Errorf("unknown option")
+		}
+
+		if err != nil {
+			return GenerateOptions{}, fmt.Errorf("%w: invalid profile option %q: %v", ErrGenerateFailed, field, err)
+		}
+	}
+
+	return options, nil
+}
+
+// String formats the options in the format read by ParseGenerateOptions.
+func (o GenerateOptions) String() string {
+	fields := []string{}
+	add := func(key, value string) {
+		if value != "" {
+			fields = append(fields, key+"="+value)
+		}
+	}
+	count := func(key string, n int) {
+		if n > 0 {
+			add(key, strconv.Itoa(n))
+		}
+	}
+	flag := func(key string, b bool) {
+		if b {
+			fields = append(fields, key)
+		}
+	}
+
+	count("size", int(o.Size))
+	add("chars", o.Chars)
+	flag("upper", o.Upper)
+	flag("lower", o.Lower)
+	flag("digits", o.Digits)
+	flag("no-special", o.NoSpecial)
+	add("special", o.Special)
+	count("min-upper", o.MinUpper)
+	count("min-lower", o.MinLower)
+	count("min-digits", o.MinDigits)
+	count("min-special", o.MinSpecial)
+	add("exclude", o.Exclude)
+
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,256 @@
+package akv
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	vaultNamePattern  = regexp.MustCompile(`^[a-zA-Z](?:-?[a-zA-Z0-9])+$`)
+	secretNamePattern = regexp.MustCompile(`^[a-zA-Z0-9-]{1,127}$`)
+	versionPattern    = regexp.MustCompile(`^[a-zA-Z0-9]{1,64}$`)
+)
+
+const appServicePrefix = "@Microsoft.KeyVault("
+
+// SecretRef identifies a secret, and optionally a version of it, in a vault.
+//
+// A reference can be written in any of these forms:
+//
+//	akv://<vault>/<name>[/<version>]
+//	https://<vault>.vault.azure.net/secrets/<name>[/<version>]
+//	@Microsoft.KeyVault(SecretUri=https://<vault>.vault.azure.net/secrets/<name>[/<version>])
+//	@Microsoft.KeyVault(VaultName=<vault>;SecretName=<name>[;SecretVersion=<version>])
+type SecretRef struct {
+	// Vault is the vault name, or the host name for vaults outside of
+	// the public cloud.
+	Vault   string
+	Name    string
+	Version string
+}
+
+// IsRef reports whether s looks like a secret reference rather than a
+// plain value. https urls only count when ParseRef accepts their host.
+func IsRef(s string) bool {
+	return IsEndpointRef(s, "")
+}
+
+// IsEndpointRef is IsRef that also accepts https urls on the host of
+// endpoint, the vault url override of ClientOptions.
+func IsEndpointRef(s, endpoint string) bool {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "akv://") || strings.HasPrefix(s, appServicePrefix) {
+		return true
+	}
+
+	if strings.HasPrefix(s, "https://") {
+		uri, err := url.Parse(s)
+		return err == nil && strings.HasPrefix(uri.Path, "/secrets/") && trustedHost(uri.Host, endpoint)
+	}
+
+	return false
+}
+
+// trustedHost reports whether references may point to host: a vault host
+// of a known cloud, or the host of endpoint. Other hosts
+// are refused so that credentials are never sent to them.
+func trustedHost(host, endpoint string) bool {
+	if _, ok := CloudFromHost(host); ok {
+		return true
+	}
+
+	if endpoint == "" {
+		return false
+	}
+
+	uri, err := url.Parse(endpoint)
+	return err == nil && uri.Host != "" && strings.EqualFold(uri.Host, host)
+}
+
+// ParseRef parses a secret reference in any of the forms supported by
+// SecretRef. The name and version may be empty; use Validate to check
+// that the reference is complete. https urls must point to a vault host of
+// a known cloud, see CloudFromHost.
+func ParseRef(s string) (SecretRef, error) {
+	return ParseEndpointRef(s, "")
+}
+
+// ParseEndpointRef is ParseRef that also accepts https urls on the host of
+// endpoint, the vault url override of ClientOptions.
+func ParseEndpointRef(s, endpoint string) (SecretRef, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, appServicePrefix) {
+		return parseAppServiceRef(s, endpoint)
+	}
+
+	uri, err := url.Parse(s)
+	if err != nil {
+		return SecretRef{}, fmt.Errorf("%w: %s", ErrInvalidURL, s)
+	}
+
+	path := strings.Trim(uri.Path, "/")
+	switch uri.Scheme {
+	case "akv":
+	case "https":
+		if path != "secrets" && !strings.HasPrefix(path, "secrets/") {
+			return SecretRef{}, fmt.Errorf("%w: %s", ErrInvalidURL, s)
+		}
+		path = strings.TrimPrefix(path, "secrets")
+		path = strings.TrimPrefix(path, "/")
+		if uri.Host != "" && !trustedHost(uri.Host, endpoint) {
+			return SecretRef{}, fmt.Errorf("%w: %q is not a key vault host", ErrInvalidURL, uri.Host)
+		}
+	default:
+		return SecretRef{}, fmt.Errorf("%w: unsupported scheme %q", ErrInvalidURL, uri.Scheme)
+	}
+
+	if uri.Host == "" {
+		return SecretRef{}, fmt.Errorf("%w: %s", ErrInvalidURL, s)
+	}
+
+	ref := SecretRef{Vault: strings.TrimSuffix(strings.ToLower(uri.Host), DefaultVaultSuffix)}
+	if _, ok := CloudFromHost(uri.Host); uri.Scheme == "https" && !ok {
+		// the endpoint host is kept as a url, which is a valid vault.
+		ref.Vault = "https://" + uri.Host
+	}
+	if path == "" {
+		return ref, nil
+	}
+
+	parts := strings.Split(path, "/")
+	if len(parts) > 2 {
+		return SecretRef{}, fmt.Errorf("%w: %s", ErrInvalidURL, s)
+	}
+
+	ref.Name = parts[0]
+	if len(parts) > 1 {
+		ref.Version = parts[1]
+	}
+
+	return ref, nil
+}
+
+func parseAppServiceRef(s, endpoint string) (SecretRef, error) {
+	if !strings.HasSuffix(s, ")") {
+		return SecretRef{}, fmt.Errorf("%w: %s", ErrInvalidURL, s)
+	}
+
+	body := strings.TrimSuffix(strings.TrimPrefix(s, appServicePrefix), ")")
+	ref := SecretRef{}
+	for _, part := range strings.Split(body, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return SecretRef{}, fmt.Errorf("%w: %s", ErrInvalidURL, s)
+		}
+
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "secreturi":
+			if !strings.HasPrefix(value, "https://") {
+				return SecretRef{}, fmt.Errorf("%w: %s", ErrInvalidURL, s)
+			}
+			return ParseEndpointRef(value, endpoint)
+		case "vaultname":
+			ref.Vault = value
+		case "secretname":
+			ref.Name = value
+		case "secretversion":
+			ref.Version = value
+		default:
+			return SecretRef{}, fmt.Errorf("%w: unknown key %q", ErrInvalidURL, key)
+		}
+	}
+
+	return ref, nil
+}
+
+// Validate checks that the reference has a vault and a name and that both,
+// and the version when set, only use the characters key vault allows.
+func (r SecretRef) Validate() error {
+	if r.Vault == "" {
+		return ErrMissingVaultName
+	}
+
+	if err := ValidateVaultName(r.Vault); err != nil {
+		return err
+	}
+
+	if r.Name == "" {
+		return ErrMissingSecretName
+	}
+
+	if err := ValidateSecretName(r.Name); err != nil {
+		return err
+	}
+
+	if r.Version != "" && !versionPattern.MatchString(r.Version) {
+		return fmt.Errorf("%w: invalid secret version %q", ErrInvalidURL, r.Version)
+	}
+
+	return nil
+}
+
+// ValidateVaultName checks a vault name, or the first label of a vault
+// host name, against the key vault naming rules. Vault urls are only
+// checked for a host.
+func ValidateVaultName(vault string) error {
+	if strings.HasPrefix(vault, "https://") || strings.HasPrefix(vault, "http://") {
+		uri, err := url.Parse(vault)
+		if err != nil || uri.Host == "" {
+			return fmt.Errorf("%w: invalid vault url %q", ErrInvalidURL, vault)
+		}
+		return nil
+	}
+
+	name, _, _ := strings.Cut(vault, ".")
+	if len(name) < 3 || len(name) > 24 || !vaultNamePattern.MatchString(name) {
+		return fmt.Errorf("%w: invalid vault name %q", ErrInvalidURL, vault)
+	}
+
+	return nil
+}
+
+// ValidateSecretName checks a secret name against the key vault naming
+// rules.
+func ValidateSecretName(name string) error {
+	if !secretNamePattern.MatchString(name) {
+		return fmt.Errorf("%w: invalid secret name %q", ErrInvalidURL, name)
+	}
+
+	return nil
+}
+
+// String formats the reference as akv://<vault>/<name>[/<version>].
+func (r SecretRef) String() string {
+	s := "akv://" + r.Vault
+	if r.Name != "" {
+		s += "/" + r.Name
+	}
+	if r.Version != "" {
+		s += "/" + r.Version
+	}
+	return s
+}
+
+// URL formats the reference as the https url of the secret. Vault names
+// get the vault suffix of c.
+func (r SecretRef) URL(c Cloud) string {
+	s := c.VaultURL(r.Vault) + "/secrets/" + r.Name
+	if r.Version != "" {
+		s += "/" + r.Version
+	}
+	return s
+}
+
+// AppService formats the reference using the App Service key vault
+// reference syntax, see URL.
+func (r SecretRef) AppService(c Cloud) string {
+	return appServicePrefix + "SecretUri=" + r.URL(c) + ")"
+}
@@ -0,0 +1,176 @@
+package akv
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseRef(t *testing.T) {
+	tests := []struct {
+		in   string
+		want SecretRef
+		err  bool
+	}{
+		{in: "akv://myvault/db-pass", want: SecretRef{Vault: "myvault", Name: "db-pass"}},
+		{in: "akv://myvault/db-pass/abc123", want: SecretRef{Vault: "myvault", Name: "db-pass", Version: "abc123"}},
+		{in: "akv://myvault", want: SecretRef{Vault: "myvault"}},
+		{in: " https://MyVault.vault.azure.net/secrets/db-pass ", want: SecretRef{Vault: "myvault", Name: "db-pass"}},
+		{in: "https://myvault.vault.azure.net/secrets/db-pass/abc123", want: SecretRef{Vault: "myvault", Name: "db-pass", Version: "abc123"}},
+		{in: "https://myvault.vault.usgovcloudapi.net/secrets/db-pass", want: SecretRef{Vault: "myvault.vault.usgovcloudapi.net", Name: "db-pass"}},
+		{in: "https://myvault.vault.azure.cn/secrets/db-pass", want: SecretRef{Vault: "myvault.vault.azure.cn", Name: "db-pass"}},
+		{in: "@Microsoft.KeyVault(SecretUri=https://myvault.vault.azure.net/secrets/db-pass/abc123)", want: SecretRef{Vault: "myvault", Name: "db-pass", Version: "abc123"}},
+		{in: "@Microsoft.KeyVault(VaultName=myvault; SecretName=db-pass; SecretVersion=abc123)", want: SecretRef{Vault: "myvault", Name: "db-pass", Version: "abc123"}},
+
+		{in: "https://myvault.vault.azure.net/keys/db-pass", err: true},
+		{in: "akv://myvault/db-pass/abc123/extra", err: true},
+		{in: "http://myvault.vault.azure.net/secrets/db-pass", err: true},
+		{in: "akv:///db-pass", err: true},
+		{in: "@Microsoft.KeyVault(VaultName=myvault", err: true},
+		{in: "@Microsoft.KeyVault(Vault=myvault)", err: true},
+		{in: "@Microsoft.KeyVault(SecretUri=akv://myvault/db-pass)", err: true},
+
+		// hosts that are not key vaults are refused so that credentials
+		// are never sent to them.
+		{in: "https://evil.example.com/secrets/db-pass", err: true},
+		{in: "https://myhsm.managedhsm.azure.net/secrets/db-pass", err: true},
+		{in: "https://127.0.0.1:8443/secrets/db-pass", err: true},
+		{in: "@Microsoft.KeyVault(SecretUri=https://evil.example.com/secrets/db-pass)", err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRef(tt.in)
+		if tt.err {
+			if !errors.Is(err, ErrInvalidURL) {
+				t.Errorf("ParseRef(%q) error = %v, want ErrInvalidURL", tt.in, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("ParseRef(%q) error = %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRef(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+		if got.Name != "" {
+			if err := got.Validate(); err != nil {
+				t.Errorf("ParseRef(%q).Validate() = %v", tt.in, err)
+			}
+		}
+	}
+}
+
+func TestIsRef(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"akv://myvault/db-pass", true},
+		{"https://myvault.vault.azure.net/secrets/db-pass", true},
+		{"@Microsoft.KeyVault(VaultName=myvault;SecretName=db-pass)", true},
+		{"https://myvault.vault.azure.net/keys/db-pass", false},
+		{"https://example.com/", false},
+		{"https://evil.example.com/secrets/db-pass", false},
+		{"plain value", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsRef(tt.in); got != tt.want {
+			t.Errorf("IsRef(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseEndpointRef(t *testing.T) {
+	const endpoint = "https://127.0.0.1:8443"
+
+	tests := []struct {
+		in       string
+		endpoint string
+		want     SecretRef
+		err      bool
+	}{
+		{in: "https://127.0.0.1:8443/secrets/db-pass", endpoint: endpoint, want: SecretRef{Vault: endpoint, Name: "db-pass"}},
+		{in: "https://127.0.0.1:8443/secrets/db-pass/abc123", endpoint: endpoint + "/", want: SecretRef{Vault: endpoint, Name: "db-pass", Version: "abc123"}},
+		{in: "@Microsoft.KeyVault(SecretUri=https://127.0.0.1:8443/secrets/db-pass)", endpoint: endpoint, want: SecretRef{Vault: endpoint, Name: "db-pass"}},
+		{in: "https://myvault.vault.azure.net/secrets/db-pass", endpoint: endpoint, want: SecretRef{Vault: "myvault", Name: "db-pass"}},
+		{in: "https://127.0.0.1:8443/secrets/db-pass", endpoint: "https://127.0.0.1:9443", err: true},
+		{in: "https://evil.example.com/secrets/db-pass", endpoint: endpoint, err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseEndpointRef(tt.in, tt.endpoint)
+		if tt.err {
+			if !errors.Is(err, ErrInvalidURL) {
+				t.Errorf("ParseEndpointRef(%q, %q) error = %v, want ErrInvalidURL", tt.in, tt.endpoint, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("ParseEndpointRef(%q, %q) error = %v", tt.in, tt.endpoint, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseEndpointRef(%q, %q) = %+v, want %+v", tt.in, tt.endpoint, got, tt.want)
+		}
+		if err := got.Validate(); err != nil {
+			t.Errorf("ParseEndpointRef(%q, %q).Validate() = %v", tt.in, tt.endpoint, err)
+		}
+	}
+
+	if !IsEndpointRef("https://localhost:8443/secrets/db-pass", "https://LOCALHOST:8443/") {
+		t.Error("IsEndpointRef does not accept the host of the endpoint")
+	}
+	if IsEndpointRef("https://localhost:8443/secrets/db-pass", "") {
+		t.Error("IsEndpointRef accepts a host without an endpoint")
+	}
+}
+
+func TestSecretRefValidate(t *testing.T) {
+	tests := []struct {
+		ref  SecretRef
+		want error
+	}{
+		{SecretRef{Vault: "myvault", Name: "db-pass"}, nil},
+		{SecretRef{Vault: "myvault", Name: "db-pass", Version: "abc123"}, nil},
+		{SecretRef{Name: "db-pass"}, ErrMissingVaultName},
+		{SecretRef{Vault: "myvault"}, ErrMissingSecretName},
+		{SecretRef{Vault: "my", Name: "db-pass"}, ErrInvalidURL},
+		{SecretRef{Vault: "my_vault", Name: "db-pass"}, ErrInvalidURL},
+		{SecretRef{Vault: "myvault", Name: "db_pass"}, ErrInvalidURL},
+		{SecretRef{Vault: "myvault", Name: "db-pass", Version: "not-a-version"}, ErrInvalidURL},
+	}
+
+	for _, tt := range tests {
+		err := tt.ref.Validate()
+		if (tt.want == nil) != (err == nil) || (tt.want != nil && !errors.Is(err, tt.want)) {
+			t.Errorf("%+v.Validate() = %v, want %v", tt.ref, err, tt.want)
+		}
+	}
+}
+
+func TestSecretRefFormats(t *testing.T) {
+	ref := SecretRef{Vault: "myvault", Name: "db-pass", Version: "abc123"}
+
+	if got, want := ref.String(), "akv://myvault/db-pass/abc123"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got, want := ref.URL(AzurePublic), "https://myvault.vault.azure.net/secrets/db-pass/abc123"; got != want {
+		t.Errorf("URL(AzurePublic) = %q, want %q", got, want)
+	}
+	if got, want := ref.URL(AzureChina), "https://myvault.vault.azure.cn/secrets/db-pass/abc123"; got != want {
+		t.Errorf("URL(AzureChina) = %q, want %q", got, want)
+	}
+	if got, want := ref.AppService(AzurePublic), "@Microsoft.KeyVault(SecretUri=https://myvault.vault.azure.net/secrets/db-pass/abc123)"; got != want {
+		t.Errorf("AppService(AzurePublic) = %q, want %q", got, want)
+	}
+
+	for _, s := range []string{ref.String(), ref.URL(AzurePublic), ref.AppService(AzurePublic)} {
+		if got, err := ParseRef(s); err != nil || got != ref {
+			t.Errorf("ParseRef(%q) = %+v, %v, want %+v", s, got, err, ref)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package akv
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+// DefaultConcurrency is the number of secrets a Resolver reads at the
+// same time unless told otherwise.
+const DefaultConcurrency = 8
+
+// Resolver reads secret references that may point to different vaults. It
+// creates one Client per vault and reuses it for every reference to that
+// vault.
+type Resolver struct {
+	credential  azcore.TokenCredential
+	options     *ClientOptions
+	concurrency int
+
+	mu      sync.Mutex
+	clients map[string]*Client
+}
+
+// NewResolver creates a resolver that authenticates with credential and
+// creates clients with options.
+func NewResolver(credential azcore.TokenCredential, options *ClientOptions) *Resolver {
+	return &Resolver{
+		credential:  credential,
+		options:     options,
+		concurrency: DefaultConcurrency,
+		clients:     map[string]*Client{},
+	}
+}
+
+// SetConcurrency sets the number of secrets ResolveAll reads at the same
+// time.
+func (r *Resolver) SetConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	r.concurrency = n
+}
+
+// Client returns the client for vault, creating it on first use.
+func (r *Resolver) Client(vault string) (*Client, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := strings.ToLower(vault)
+	if c, ok := r.clients[key]; ok {
+		return c, nil
+	}
+
+	c, err := NewClient(vault, r.credential, r.options)
+	if err != nil {
+		return nil, err
+	}
+
+	r.clients[key] = c
+	return c, nil
+}
+
+// IsRef reports whether s is a secret reference the resolver reads, see
+// IsEndpointRef.
+func (r *Resolver) IsRef(s string) bool {
+	return IsEndpointRef(s, r.endpoint())
+}
+
+// ParseRef parses a secret reference the resolver reads, see
+// ParseEndpointRef.
+func (r *Resolver) ParseRef(s string) (SecretRef, error) {
+	return ParseEndpointRef(s, r.endpoint())
+}
+
+func (r *Resolver) endpoint() string {
+	if r.options == nil {
+		return ""
+	}
+	return r.options.Endpoint
+}
+
+// Get reads the secret ref points to.
+func (r *Resolver) Get(ctx context.Context, ref SecretRef) (*Secret, error) {
+	if err := ref.Validate(); err != nil {
+		return nil, newError("get", ref.Vault, ref.Name, err)
+	}
+
+	c, err := r.Client(ref.Vault)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Get(ctx, ref.Name, ref.Version)
+}
+
+// Value parses the reference s and returns the value of the secret.
+func (r *Resolver) Value(ctx context.Context, s string) (string, error) {
+	ref, err := r.ParseRef(s)
+	if err != nil {
+		return "", newError("get", "", "", err)
+	}
+
+	secret, err := r.Get(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+
+	return secret.Value, nil
+}
+
+// ResolveAll replaces every value in values that is a secret reference
+// with the value of the secret and returns the result as a new map. Other
+// values are copied as they are. Secrets are read concurrently and each
+// distinct reference is only read once.
+func (r *Resolver) ResolveAll(ctx context.Context, values map[string]string) (map[string]string, error) {
+	refs := map[string]string{}
+	for _, v := range values {
+		if r.IsRef(v) {
+			refs[v] = ""
+		}
+	}
+
+	keys := make([]string, 0, len(refs))
+	for ref := range refs {
+		keys = append(keys, ref)
+	}
+
+	resolved := make([]string, len(keys))
+	err := forEach(ctx, len(keys), r.concurrency, func(ctx context.Context, i int) error {
+		value, err := r.Value(ctx, keys[i])
+		if err != nil {
+			return err
+		}
+
+		resolved[i] = value
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, ref := range keys {
+		refs[ref] = resolved[i]
+	}
+
+	res := make(map[string]string, len(values))
+	for k, v := range values {
+		if r.IsRef(v) {
+			res[k] = refs[v]
+		} else {
+			res[k] = v
+		}
+	}
+
+	return res, nil
+}
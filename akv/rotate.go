@@ -0,0 +1,348 @@
+package akv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mashiike/longduration"
+)
+
+// Tags that hold the rotation policy of a secret.
+const (
+	// TagRotateEvery is how long a generated value is valid, e.g. 90d. A
+	// secret without it has no rotation policy.
+	TagRotateEvery = "rotate-every"
+	// TagRotateBefore is how long before its expiry a value is rotated.
+	TagRotateBefore = "rotate-before"
+	// TagLength is the length of generated values.
+	TagLength = "length"
+	// TagCharset is a named character set or generator profile, see
+	// RotationPolicy.Charset.
+	TagCharset = "charset"
+
+	// literalCharsetPrefix marks a charset that lists the characters to
+	// use instead of naming them.
+	literalCharsetPrefix = "chars:"
+)
+
+// Named character sets of the charset tag.
+var charsets = map[string]GenerateOptions{
+	"default": NistGenerateOptions(0),
+	"alnum":   {Upper: true, Lower: true, Digits: true, NoSpecial: true},
+	"alpha":   {Upper: true, Lower: true, NoSpecial: true},
+	"hex":     {Chars: "0123456789abcdef"},
+	"digits":  {Chars: "0123456789"},
+}
+
+// ProfileLookup returns the options of a named generator profile, and
+// false when there is no profile of that name. Applications use it to add
+// their own profiles to the built-in Profiles.
+type ProfileLookup func(name string) (GenerateOptions, bool, error)
+
+// BuiltinProfile is the ProfileLookup of the built-in Profiles.
+func BuiltinProfile(name string) (GenerateOptions, bool, error) {
+	options, ok := Profiles[strings.ToLower(name)]
+	return options, ok, nil
+}
+
+// RotationPolicy describes when and how a secret is regenerated. It is
+// stored in the tags of the secret so that it survives each rotation.
+type RotationPolicy struct {
+	// Every is how long a generated value is valid. Rotated versions
+	// expire Every after they are created.
+	Every time.Duration
+	// Before is how long before its expiry a value is due for rotation.
+	Before time.Duration
+	// Length is the length of generated values. When zero, profiles keep
+	// their own size and character sets use 32.
+	Length int16
+	// Charset is the name of a generator profile, such as nist, sql-server
+	// or url-safe, or one of the character sets default, alnum, alpha, hex
+	// and digits. "chars:" followed by characters uses exactly those
+	// characters. It is default when empty.
+	Charset string
+	// Dual rotates the two slots of a DualSecret in turn instead of the
+	// secret itself.
+	Dual bool
+}
+
+// RotationHook propagates a rotated value, for example to the database
+// that uses it. It is called with the previous version and the new,
+// still disabled, version of the secret before the new version is enabled.
+// Returning an error rolls the rotation back.
+type RotationHook func(ctx context.Context, previous, next *Secret) error
+
+// RotateOptions contains optional settings for Client.Rotate.
+type RotateOptions struct {
+	// Policy overrides the policy read from the tags of the secret.
+	Policy *RotationPolicy
+	// Hook is called before the new version is enabled.
+	Hook RotationHook
+	// Profiles resolves the charset of the policy, BuiltinProfile when nil.
+	Profiles ProfileLookup
+}
+
+// ParseRotationPolicy reads the rotation policy from the tags of a secret.
+// It returns nil without an error when the tags have no rotate-every tag.
+func ParseRotationPolicy(tags map[string]string) (*RotationPolicy, error) {
+	every := tags[TagRotateEvery]
+	if every == "" {
+		return nil, nil
+	}
+
+	policy := &RotationPolicy{Charset: tags[TagCharset]}
+
+	switch mode := tags[TagRotateMode]; mode {
+	case "", "single":
+	case "dual":
+		policy.Dual = true
+	default:
+		return nil, fmt.Errorf("%w: %s %q is not single or dual", ErrInvalidPolicy, TagRotateMode, mode)
+	}
+
+	dur, err := longduration.ParseDuration(every)
+	if err != nil || dur <= 0 {
+		return nil, fmt.Errorf("%w: %s %q is not a duration", ErrInvalidPolicy, TagRotateEvery, every)
+	}
+	policy.Every = dur
+
+	if before := tags[TagRotateBefore]; before != "" {
+		dur, err := longduration.ParseDuration(before)
+		if err != nil || dur < 0 {
+			return nil, fmt.Errorf("%w: %s %q is not a duration", ErrInvalidPolicy, TagRotateBefore, before)
+		}
+		policy.Before = dur
+	}
+
+	if length := tags[TagLength]; length != "" {
+		n, err := strconv.ParseInt(length, 10, 16)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("%w: %s %q is not a positive number", ErrInvalidPolicy, TagLength, length)
+		}
+		policy.Length = int16(n)
+	}
+
+	return policy, nil
+}
+
+// Tags returns the policy as tags.
+func (p *RotationPolicy) Tags() map[string]string {
+	tags := map[string]string{
+		TagRotateEvery: formatDuration(p.Every),
+	}
+
+	if p.Length > 0 {
+		tags[TagLength] = strconv.Itoa(int(p.Length))
+	}
+
+	if p.Before > 0 {
+		tags[TagRotateBefore] = formatDuration(p.Before)
+	}
+
+	if p.Charset != "" {
+		tags[TagCharset] = p.Charset
+	}
+
+	if p.Dual {
+		tags[TagRotateMode] = "dual"
+	}
+
+	return tags
+}
+
+// GenerateOptions returns the options used to generate new values. The
+// charset is looked up with profiles, BuiltinProfile when nil, before the
+// character sets; unknown names are an ErrInvalidPolicy error.
+func (p *RotationPolicy) GenerateOptions(profiles ProfileLookup) (GenerateOptions, error) {
+	if profiles == nil {
+		profiles = BuiltinProfile
+	}
+
+	length := p.Length
+	if length <= 0 {
+		length = 32
+	}
+
+	if chars, ok := strings.CutPrefix(p.Charset, literalCharsetPrefix); ok {
+		if chars == "" {
+			return GenerateOptions{}, fmt.Errorf("%w: %s %q has no characters", ErrInvalidPolicy, TagCharset, p.Charset)
+		}
+		return GenerateOptions{Size: length, Chars: chars}, nil
+	}
+
+	name := strings.ToLower(p.Charset)
+	if name == "" {
+		name = "default"
+	}
+
+	options, ok, err := profiles(name)
+	if err != nil {
+		return GenerateOptions{}, fmt.Errorf("%w: %s %q: %v", ErrInvalidPolicy, TagCharset, p.Charset, err)
+	}
+	if ok {
+		if p.Length > 0 || options.Size <= 0 {
+			options.Size = length
+		}
+		return options, nil
+	}
+
+	options, ok = charsets[name]
+	if !ok {
+		return GenerateOptions{}, fmt.Errorf("%w: unknown %s %q, use a profile, a character set or %s followed by the characters", ErrInvalidPolicy, TagCharset, p.Charset, literalCharsetPrefix)
+	}
+
+	options.Size = length
+	return options, nil
+}
+
+// Due reports whether a secret with the given properties must be rotated
+// at now: when it expires within Before, or, without an expiry date, when
+// it was last updated more than Every ago. For dual policies props are
+// those of the active slot, which expires one period after the next
+// rotation.
+func (p *RotationPolicy) Due(props *SecretProperties, now time.Time) bool {
+	if props.Expires != nil {
+		expires := *props.Expires
+		if p.Dual {
+			expires = expires.Add(-p.Every)
+		}
+		return !now.Before(expires.Add(-p.Before))
+	}
+
+	last := props.Updated
+	if last == nil {
+		last = props.Created
+	}
+
+	return last == nil || !now.Before(last.Add(p.Every-p.Before))
+}
+
+// formatDuration formats d in days when it is a whole number of days.
+func formatDuration(d time.Duration) string {
+	day := 24 * time.Hour
+	if d%day == 0 {
+		return strconv.FormatInt(int64(d/day), 10) + "d"
+	}
+	return d.String()
+}
+
+// Rotate writes a new generated value for the secret following its
+// rotation policy. The new version keeps the content type and tags of the
+// current one, with the policy written back to the tags, and expires one
+// rotation period from now. Rotate does not check whether the secret is
+// due, see RotationPolicy.Due.
+//
+// With a hook the new version is written disabled and only enabled once
+// the hook succeeds. When the hook fails the new version stays disabled,
+// the previous value is written back as the latest version and an error
+// with the ErrHookFailed kind is returned.
+func (c *Client) Rotate(ctx context.Context, name string, options *RotateOptions) (*Secret, error) {
+	if options == nil {
+		options = &RotateOptions{}
+	}
+
+	current, err := c.Get(ctx, name, "")
+	if err != nil {
+		return nil, err
+	}
+
+	policy := options.Policy
+	if policy == nil {
+		policy, err = ParseRotationPolicy(current.Tags)
+		if err != nil {
+			return nil, newError("rotate", c.vault, name, err)
+		}
+		if policy == nil {
+			return nil, &Error{Op: "rotate", Vault: c.vault, Name: name, Kind: ErrInvalidPolicy,
+				Err: errors.New("the secret has no " + TagRotateEvery + " tag")}
+		}
+	}
+
+	return c.rotate(ctx, current, policy, options)
+}
+
+func (c *Client) rotate(ctx context.Context, current *Secret, policy *RotationPolicy, options *RotateOptions) (*Secret, error) {
+	generate, err := policy.GenerateOptions(options.Profiles)
+	if err != nil {
+		return nil, newError("rotate", c.vault, current.Name, err)
+	}
+
+	value, err := Generate(generate)
+	if err != nil {
+		return nil, newError("rotate", c.vault, current.Name, err)
+	}
+
+	tags := map[string]string{}
+	maps.Copy(tags, current.Tags)
+	maps.Copy(tags, policy.Tags())
+
+	expires := time.Now().Add(policy.Every)
+	return c.replace(ctx, "rotate", current, value, &SetOptions{
+		ContentType: current.ContentType,
+		Expires:     &expires,
+		Tags:        tags,
+	}, options.Hook)
+}
+
+// replace writes value as the new version of the current secret, passing
+// it through hook before it is enabled when hook is not nil. A current
+// secret without a version does not exist yet and is left disabled when
+// the hook fails; GetDual treats such slots as absent.
+func (c *Client) replace(ctx context.Context, op string, current *Secret, value string, options *SetOptions, hook RotationHook) (*Secret, error) {
+	if hook == nil {
+		return c.Set(ctx, current.Name, value, options)
+	}
+
+	disabled := *options
+	enabled := false
+	disabled.Enabled = &enabled
+
+	next, err := c.Set(ctx, current.Name, value, &disabled)
+	if err != nil {
+		return nil, err
+	}
+	next.Value = value
+
+	if err := hook(ctx, current, next); err != nil {
+		err = &Error{Op: op, Vault: c.vault, Name: current.Name, Kind: ErrHookFailed, Err: err}
+		if current.Version == "" {
+			return nil, err
+		}
+		return nil, c.restoreCurrent(ctx, current, err)
+	}
+
+	enabled = true
+	props, err := c.Update(ctx, current.Name, next.Version, &UpdateOptions{Enabled: &enabled})
+	if err != nil {
+		if current.Version == "" {
+			return nil, err
+		}
+		return nil, c.restoreCurrent(ctx, current, err)
+	}
+
+	next.SecretProperties = *props
+	return next, nil
+}
+
+// restoreCurrent writes the value and properties of current back as the
+// latest version after a failed rotation and returns cause.
+func (c *Client) restoreCurrent(ctx context.Context, current *Secret, cause error) error {
+	enabled := current.Enabled
+	_, err := c.Set(ctx, current.Name, current.Value, &SetOptions{
+		ContentType: current.ContentType,
+		Enabled:     &enabled,
+		NotBefore:   current.NotBefore,
+		Expires:     current.Expires,
+		Tags:        current.Tags,
+	})
+	if err != nil {
+		return errors.Join(cause, fmt.Errorf("failed to restore the previous version of %s: %w", current.Name, err))
+	}
+
+	return cause
+}
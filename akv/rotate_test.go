@@ -0,0 +1,163 @@
+package akv
+
+import (
+	"errors"
+	"maps"
+	"testing"
+	"time"
+)
+
+func TestParseRotationPolicy(t *testing.T) {
+	day := 24 * time.Hour
+
+	tests := []struct {
+		tags map[string]string
+		want *RotationPolicy
+		err  bool
+	}{
+		{tags: nil, want: nil},
+		{tags: map[string]string{"env": "prod"}, want: nil},
+		{tags: map[string]string{TagRotateEvery: "90d"}, want: &RotationPolicy{Every: 90 * day}},
+		{
+			tags: map[string]string{TagRotateEvery: "30d", TagRotateBefore: "7d", TagLength: "24", TagCharset: "hex", TagRotateMode: "dual"},
+			want: &RotationPolicy{Every: 30 * day, Before: 7 * day, Length: 24, Charset: "hex", Dual: true},
+		},
+		{tags: map[string]string{TagRotateEvery: "12h", TagRotateMode: "single"}, want: &RotationPolicy{Every: 12 * time.Hour}},
+		{tags: map[string]string{TagRotateEvery: "soon"}, err: true},
+		{tags: map[string]string{TagRotateEvery: "0d"}, err: true},
+		{tags: map[string]string{TagRotateEvery: "30d", TagRotateBefore: "later"}, err: true},
+		{tags: map[string]string{TagRotateEvery: "30d", TagLength: "0"}, err: true},
+		{tags: map[string]string{TagRotateEvery: "30d", TagLength: "many"}, err: true},
+		{tags: map[string]string{TagRotateEvery: "30d", TagRotateMode: "triple"}, err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRotationPolicy(tt.tags)
+		if tt.err {
+			if !errors.Is(err, ErrInvalidPolicy) {
+				t.Errorf("ParseRotationPolicy(%v) error = %v, want ErrInvalidPolicy", tt.tags, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("ParseRotationPolicy(%v) error = %v", tt.tags, err)
+			continue
+		}
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("ParseRotationPolicy(%v) = %+v, want %+v", tt.tags, got, tt.want)
+		}
+		if got == nil {
+			continue
+		}
+
+		again, err := ParseRotationPolicy(got.Tags())
+		if err != nil || *again != *got {
+			t.Errorf("ParseRotationPolicy(%+v.Tags()) = %+v, %v", got, again, err)
+		}
+	}
+}
+
+func TestRotationPolicyDue(t *testing.T) {
+	day := 24 * time.Hour
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+
+	single := &RotationPolicy{Every: 30 * day, Before: 7 * day}
+	dual := &RotationPolicy{Every: 30 * day, Before: 7 * day, Dual: true}
+
+	tests := []struct {
+		name   string
+		policy *RotationPolicy
+		props  SecretProperties
+		want   bool
+	}{
+		{"expires later", single, SecretProperties{Expires: at(8 * day)}, false},
+		{"expires within before", single, SecretProperties{Expires: at(7 * day)}, true},
+		{"expired", single, SecretProperties{Expires: at(-day)}, true},
+		{"updated recently", single, SecretProperties{Updated: at(-10 * day)}, false},
+		{"updated long ago", single, SecretProperties{Updated: at(-23 * day)}, true},
+		{"created long ago", single, SecretProperties{Created: at(-25 * day)}, true},
+		{"updated wins over created", single, SecretProperties{Created: at(-25 * day), Updated: at(-day)}, false},
+		{"no dates", single, SecretProperties{}, true},
+		// the active slot of a dual secret expires one period after the
+		// next rotation.
+		{"dual active slot", dual, SecretProperties{Expires: at(38 * day)}, false},
+		{"dual next rotation within before", dual, SecretProperties{Expires: at(37 * day)}, true},
+		{"dual without expiry", dual, SecretProperties{Updated: at(-23 * day)}, true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.policy.Due(&tt.props, now); got != tt.want {
+			t.Errorf("%s: Due() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRotationPolicyGenerateOptions(t *testing.T) {
+	custom := func(name string) (GenerateOptions, bool, error) {
+		if name == "pin" {
+			return GenerateOptions{Size: 6, Chars: "0123456789"}, true, nil
+		}
+		return BuiltinProfile(name)
+	}
+
+	tests := []struct {
+		policy   RotationPolicy
+		profiles ProfileLookup
+		want     GenerateOptions
+		err      bool
+	}{
+		{policy: RotationPolicy{}, want: NistGenerateOptions(32)},
+		{policy: RotationPolicy{Charset: "nist", Length: 20}, want: NistGenerateOptions(20)},
+		{policy: RotationPolicy{Charset: "hex"}, want: GenerateOptions{Size: 32, Chars: "0123456789abcdef"}},
+		{policy: RotationPolicy{Charset: "HEX", Length: 8}, want: GenerateOptions{Size: 8, Chars: "0123456789abcdef"}},
+		{policy: RotationPolicy{Charset: "alnum"}, want: GenerateOptions{Size: 32, Upper: true, Lower: true, Digits: true, NoSpecial: true}},
+		{policy: RotationPolicy{Charset: "chars:ab"}, want: GenerateOptions{Size: 32, Chars: "ab"}},
+		{policy: RotationPolicy{Charset: "url-safe"}, want: Profiles["url-safe"]},
+		{policy: RotationPolicy{Charset: "sql-server", Length: 40}, want: withSize(Profiles["sql-server"], 40)},
+		// profiles keep their own size unless the policy has a length.
+		{policy: RotationPolicy{Charset: "hex64"}, want: Profiles["hex64"]},
+		{policy: RotationPolicy{Charset: "hex64", Length: 16}, want: withSize(Profiles["hex64"], 16)},
+		{policy: RotationPolicy{Charset: "pin"}, profiles: custom, want: GenerateOptions{Size: 6, Chars: "0123456789"}},
+		{policy: RotationPolicy{Charset: "pin"}, err: true},
+		{policy: RotationPolicy{Charset: "ab"}, err: true},
+		{policy: RotationPolicy{Charset: "chars:"}, err: true},
+		{policy: RotationPolicy{Charset: "nope"}, profiles: custom, err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.policy.GenerateOptions(tt.profiles)
+		if tt.err {
+			if !errors.Is(err, ErrInvalidPolicy) {
+				t.Errorf("%+v.GenerateOptions() error = %v, want ErrInvalidPolicy", tt.policy, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%+v.GenerateOptions() error = %v", tt.policy, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%+v.GenerateOptions() = %+v, want %+v", tt.policy, got, tt.want)
+		}
+	}
+}
+
+func withSize(options GenerateOptions, size int16) GenerateOptions {
+	options.Size = size
+	return options
+}
+
+func TestRotationPolicyTags(t *testing.T) {
+	policy := &RotationPolicy{Every: 36 * time.Hour, Charset: "alnum"}
+	want := map[string]string{TagRotateEvery: "36h0m0s", TagCharset: "alnum"}
+
+	if got := policy.Tags(); !maps.Equal(got, want) {
+		t.Errorf("Tags() = %v, want %v", got, want)
+	}
+}
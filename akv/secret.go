@@ -0,0 +1,109 @@
+package akv
+
+import (
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+)
+
+// SecretProperties holds the metadata of a secret without its value.
+type SecretProperties struct {
+	Name        string
+	Version     string
+	ID          string
+	ContentType string
+	Enabled     bool
+	Managed     bool
+	NotBefore   *time.Time
+	Expires     *time.Time
+	Created     *time.Time
+	Updated     *time.Time
+	Tags        map[string]string
+}
+
+// Secret is a secret value together with its properties.
+type Secret struct {
+	SecretProperties
+	Value string
+}
+
+// IsExpired reports whether the secret has an expiry date that is not
+// after now.
+func (p *SecretProperties) IsExpired(now time.Time) bool {
+	return p.Expires != nil && !now.Before(*p.Expires)
+}
+
+// Tag returns the value of the tag with the given name.
+func (p *SecretProperties) Tag(name string) string {
+	if p.Tags == nil {
+		return ""
+	}
+
+	return p.Tags[name]
+}
+
+func newProperties(id *azsecrets.ID, contentType *string, attrs *azsecrets.SecretAttributes, tags map[string]*string, managed *bool) SecretProperties {
+	props := SecretProperties{}
+	if id != nil {
+		props.ID = string(*id)
+		props.Name = id.Name()
+		props.Version = id.Version()
+	}
+
+	if contentType != nil {
+		props.ContentType = *contentType
+	}
+
+	if managed != nil {
+		props.Managed = *managed
+	}
+
+	if attrs != nil {
+		if attrs.Enabled != nil {
+			props.Enabled = *attrs.Enabled
+		}
+		props.NotBefore = attrs.NotBefore
+		props.Expires = attrs.Expires
+		props.Created = attrs.Created
+		props.Updated = attrs.Updated
+	}
+
+	if len(tags) > 0 {
+		props.Tags = make(map[string]string, len(tags))
+		for k, v := range tags {
+			if v != nil {
+				props.Tags[k] = *v
+			} else {
+				props.Tags[k] = ""
+			}
+		}
+	}
+
+	return props
+}
+
+func newSecret(s azsecrets.Secret) *Secret {
+	secret := &Secret{
+		SecretProperties: newProperties(s.ID, s.ContentType, s.Attributes, s.Tags, s.Managed),
+	}
+
+	if s.Value != nil {
+		secret.Value = *s.Value
+	}
+
+	return secret
+}
+
+func toTags(tags map[string]string) map[string]*string {
+	if tags == nil {
+		return nil
+	}
+
+	res := make(map[string]*string, len(tags))
+	for k, v := range tags {
+		value := v
+		res[k] = &value
+	}
+
+	return res
+}
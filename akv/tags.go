@@ -0,0 +1,31 @@
+package akv
+
+import (
+	"context"
+	"maps"
+)
+
+// MergeTags reads the tags of a version of a secret, sets the tags in set,
+// removes the tags named in remove and writes the result back without
+// creating a new version. The latest version is used when version is
+// empty.
+func (c *Client) MergeTags(ctx context.Context, name, version string, set map[string]string, remove []string) (*SecretProperties, error) {
+	secret, err := c.Get(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := map[string]string{}
+	maps.Copy(tags, secret.Tags)
+	maps.Copy(tags, set)
+	for _, key := range remove {
+		delete(tags, key)
+	}
+
+	return c.Update(ctx, name, secret.Version, &UpdateOptions{Tags: tags})
+}
+
+// ClearTags removes every tag of a version of a secret.
+func (c *Client) ClearTags(ctx context.Context, name, version string) (*SecretProperties, error) {
+	return c.Update(ctx, name, version, &UpdateOptions{Tags: map[string]string{}})
+}
@@ -0,0 +1,329 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hyprxlabs/secrets-akv/akv"
+	"github.com/spf13/cobra"
+)
+
+const backupManifestFile = "manifest.json"
+
+// backupManifest describes the secrets of a backup directory or archive.
+type backupManifest struct {
+	Vault   string        `json:"vault"`
+	URL     string        `json:"url"`
+	Created time.Time     `json:"created"`
+	Secrets []backupEntry `json:"secrets"`
+}
+
+type backupEntry struct {
+	Name   string `json:"name"`
+	File   string `json:"file"`
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// backupCmd represents the backup command
+var backupCmd = &cobra.Command{
+	Use:   "backup [url]",
+	Short: "Backs up secrets using key vault's protected backup blobs",
+	Long: `Backs up a single secret, or every secret matching --query, using key
+vault's native backup. Each blob holds every version of a secret and is
+encrypted by key vault; it can only be restored into a vault in the same
+subscription and geography.
+
+The blobs and a manifest.json describing them are written to a directory,
+or to a tar archive when --output ends in .tar, .tar.gz or .tgz. Managed
+secrets, such as those backing certificates, are skipped.`,
+	Example: `hx-secrets-akv backup akv://myvault/db-pass --output ./backup
+hx-secrets-akv backup --vault myvault --query 'app-*' --output app-secrets.tar.gz
+hx-secrets-akv backup --vault myvault --all --output myvault.tar`,
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		query, _ := cmd.Flags().GetString("query")
+		all, _ := cmd.Flags().GetBool("all")
+
+		ref := secretRef(cmd, args)
+		if ref.Vault == "" {
+			ref.Vault = flagOrEnv(cmd, "endpoint", "HX_AKV_ENDPOINT")
+		}
+
+		if ref.Vault == "" {
+			exitWithError(cmd, akv.ErrMissingVaultName)
+		}
+
+		if err := akv.ValidateVaultName(ref.Vault); err != nil {
+			exitWithError(cmd, err)
+		}
+
+		if ref.Name == "" && query == "" && !all {
+			cmd.PrintErrf("Error: a secret name, --query or --all is required\n")
+			os.Exit(CODE_MISSING_VAULT_SECRET_NAME)
+		}
+
+		client := newClient(cmd, ref.Vault)
+
+		names := []string{}
+		if ref.Name != "" && !strings.ContainsAny(ref.Name, "*?[") {
+			names = append(names, ref.Name)
+		} else {
+			if ref.Name != "" {
+				query = ref.Name
+			}
+
+			list, err := client.List(cmd.Context(), query)
+			if err != nil {
+				exitWithError(cmd, err)
+			}
+
+			for _, props := range list {
+				if !props.Managed {
+					names = append(names, props.Name)
+				}
+			}
+		}
+
+		if output == "" {
+			output = fmt.Sprintf("%s-backup-%s", akv.SecretName(ref.Vault), time.Now().UTC().Format("20060102T150405Z"))
+		}
+
+		manifest := backupManifest{
+			Vault:   ref.Vault,
+			URL:     client.URL(),
+			Created: time.Now().UTC(),
+			Secrets: []backupEntry{},
+		}
+
+		blobs := map[string][]byte{}
+		for _, name := range names {
+			blob, err := client.Backup(cmd.Context(), name)
+			if err != nil {
+				exitWithError(cmd, err)
+			}
+
+			sum := sha256.Sum256(blob)
+			entry := backupEntry{
+				Name:   name,
+				File:   path.Join("secrets", name+".blob"),
+				Size:   len(blob),
+				SHA256: hex.EncodeToString(sum[:]),
+			}
+
+			manifest.Secrets = append(manifest.Secrets, entry)
+			blobs[entry.File] = blob
+		}
+
+		if err := writeBackup(output, manifest, blobs); err != nil {
+			cmd.PrintErrf("Failed to write backup %s: %v\n", output, err)
+			os.Exit(CODE_ERROR)
+		}
+
+		cmd.Printf("Backed up %d secrets to %s\n", len(manifest.Secrets), output)
+		os.Exit(CODE_OK)
+	},
+}
+
+// isArchive reports whether a backup path is a tar archive rather than a
+// directory.
+func isArchive(file string) bool {
+	lower := strings.ToLower(file)
+	return strings.HasSuffix(lower, ".tar") || strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz")
+}
+
+// writeBackup writes the manifest and blobs to a directory or tar archive.
+// Only the owner can read the result.
+func writeBackup(output string, manifest backupManifest, blobs map[string][]byte) error {
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if !isArchive(output) {
+		if err := os.MkdirAll(filepath.Join(output, "secrets"), 0700); err != nil {
+			return err
+		}
+
+		for file, blob := range blobs {
+			if err := writeSecretFile(filepath.Join(output, filepath.FromSlash(file)), blob); err != nil {
+				return err
+			}
+		}
+
+		return writeSecretFile(filepath.Join(output, backupManifestFile), manifestBytes)
+	}
+
+	f, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var w io.Writer = f
+	var gz *gzip.Writer
+	if !strings.HasSuffix(strings.ToLower(output), ".tar") {
+		gz = gzip.NewWriter(f)
+		w = gz
+	}
+
+	tw := tar.NewWriter(w)
+	add := func(name string, data []byte) error {
+		header := &tar.Header{
+			Name:    name,
+			Mode:    0600,
+			Size:    int64(len(data)),
+			ModTime: manifest.Created,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	// the manifest goes first so that readers can check what follows.
+	if err := add(backupManifestFile, manifestBytes); err != nil {
+		return err
+	}
+
+	for _, entry := range manifest.Secrets {
+		if err := add(entry.File, blobs[entry.File]); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return err
+		}
+	}
+
+	return f.Close()
+}
+
+// readBackup reads the manifest and blobs of a backup directory or tar
+// archive, gzip compressed or not, and checks the blobs against the
+// manifest.
+func readBackup(input string) (*backupManifest, map[string][]byte, error) {
+	files := map[string][]byte{}
+
+	info, err := os.Stat(input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if info.IsDir() {
+		bits, err := os.ReadFile(filepath.Join(input, backupManifestFile))
+		if err != nil {
+			return nil, nil, err
+		}
+		files[backupManifestFile] = bits
+	} else {
+		f, err := os.Open(input)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer f.Close()
+
+		br := bufio.NewReader(f)
+		var r io.Reader = br
+		if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+			gz, err := gzip.NewReader(br)
+			if err != nil {
+				return nil, nil, err
+			}
+			defer gz.Close()
+			r = gz
+		}
+
+		tr := tar.NewReader(r)
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if header.Typeflag != tar.TypeReg {
+				continue
+			}
+
+			bits, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, nil, err
+			}
+			files[path.Clean(header.Name)] = bits
+		}
+	}
+
+	manifest := &backupManifest{}
+	bits, ok := files[backupManifestFile]
+	if !ok {
+		return nil, nil, fmt.Errorf("%s not found", backupManifestFile)
+	}
+
+	if err := json.Unmarshal(bits, manifest); err != nil {
+		return nil, nil, fmt.Errorf("invalid %s: %w", backupManifestFile, err)
+	}
+
+	blobs := map[string][]byte{}
+	for _, entry := range manifest.Secrets {
+		file := path.Clean(entry.File)
+		if path.IsAbs(file) || strings.HasPrefix(file, "..") {
+			return nil, nil, fmt.Errorf("invalid file %q for secret %s", entry.File, entry.Name)
+		}
+
+		blob, ok := files[file]
+		if !ok && info.IsDir() {
+			blob, err = os.ReadFile(filepath.Join(input, filepath.FromSlash(file)))
+			ok = err == nil
+		}
+
+		if !ok {
+			return nil, nil, fmt.Errorf("blob %s for secret %s not found", entry.File, entry.Name)
+		}
+
+		sum := sha256.Sum256(blob)
+		if entry.SHA256 != "" && hex.EncodeToString(sum[:]) != entry.SHA256 {
+			return nil, nil, fmt.Errorf("checksum of %s does not match the manifest", entry.File)
+		}
+
+		blobs[entry.File] = blob
+	}
+
+	return manifest, blobs, nil
+}
+
+func init() {
+	backupCmd.Flags().StringP("vault", "v", "", "The name of the Azure Key Vault")
+	backupCmd.Flags().StringP("key", "k", "", "Name of the secret to back up")
+	backupCmd.Flags().StringP("query", "s", "", "Back up the secrets whose name matches the query")
+	backupCmd.Flags().Bool("all", false, "Back up every secret in the vault")
+	backupCmd.Flags().StringP("output", "o", "", "Directory or .tar, .tar.gz or .tgz file to write to (default <vault>-backup-<time>)")
+	backupCmd.Flags().BoolP("interactive", "i", false, "Use interactive authentication")
+	backupCmd.Flags().Bool("device-code", false, "Use device code authentication")
+
+	rootCmd.AddCommand(backupCmd)
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity/cache"
 )
@@ -58,13 +59,15 @@ func getRecordPath() string {
 	return filepath.Join(targetDir, "credential.cache.json")
 }
 
-func newAzInteractive(ctx context.Context) (*azidentity.InteractiveBrowserCredential, error) {
+func newAzInteractive(ctx context.Context, clientOptions azcore.ClientOptions, tenant string) (*azidentity.InteractiveBrowserCredential, error) {
 	record, err := retrieveRecord()
 	if err != nil {
 		return nil, err
 	}
 
 	cred, err := azidentity.NewInteractiveBrowserCredential(&azidentity.InteractiveBrowserCredentialOptions{
+		ClientOptions: clientOptions,
+		TenantID:      tenant,
 		// If record is zero, the credential will start with no user logged in
 		AuthenticationRecord: record,
 		// Credentials cache in memory by default. Setting Cache with a
@@ -89,7 +92,7 @@ func newAzInteractive(ctx context.Context) (*azidentity.InteractiveBrowserCreden
 	return cred, nil
 }
 
-func newDeviceCode(ctx context.Context) (*azidentity.DeviceCodeCredential, error) {
+func newDeviceCode(ctx context.Context, clientOptions azcore.ClientOptions, tenant string) (*azidentity.DeviceCodeCredential, error) {
 	record, err := retrieveRecord()
 	if err != nil {
 		return nil, err
@@ -101,6 +104,8 @@ func newDeviceCode(ctx context.Context) (*azidentity.DeviceCodeCredential, error
 	}
 
 	cred, err := azidentity.NewDeviceCodeCredential(&azidentity.DeviceCodeCredentialOptions{
+		ClientOptions: clientOptions,
+		TenantID:      tenant,
 		// If record is zero, the credential will start with no user logged in
 		AuthenticationRecord: record,
 		// Credentials cache in memory by default. Setting Cache with a
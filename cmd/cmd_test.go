@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/hyprxlabs/secrets-akv/emulator"
+)
+
+// commands exit the process, so tests run the cli in a child process of
+// the test binary. TestMain runs the command when cliVariable is set.
+const cliVariable = "HX_AKV_TEST_CLI"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(cliVariable) == "1" {
+		Execute()
+		os.Exit(CODE_OK)
+	}
+
+	os.Exit(m.Run())
+}
+
+// testVault is an emulator on a httptest TLS server whose certificate is
+// written to caFile.
+type testVault struct {
+	url    string
+	caFile string
+	token  string
+	home   string
+}
+
+func newTestVault(t *testing.T) *testVault {
+	t.Helper()
+
+	dir := t.TempDir()
+	store, err := emulator.OpenStore(filepath.Join(dir, "secrets.json"))
+	if err != nil {
+		t.Fatalf("OpenStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	srv := httptest.NewTLSServer(emulator.NewServer(store, &emulator.Options{Token: "test-token"}))
+	t.Cleanup(srv.Close)
+
+	caFile := filepath.Join(dir, "emulator.pem")
+	block := &pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatalf("write ca file: %v", err)
+	}
+
+	home := filepath.Join(dir, "home")
+	if err := os.Mkdir(home, 0700); err != nil {
+		t.Fatalf("create home: %v", err)
+	}
+
+	return &testVault{url: srv.URL, caFile: caFile, token: "test-token", home: home}
+}
+
+// run runs the cli with args against the vault and returns its stdout,
+// stderr and exit code.
+func (v *testVault) run(t *testing.T, args ...string) (string, string, int) {
+	t.Helper()
+
+	args = append(args, "--endpoint", v.url, "--access-token", v.token, "--ca-file", v.caFile)
+	child := exec.Command(os.Args[0], args...)
+	child.Env = []string{
+		cliVariable + "=1",
+		"HOME=" + v.home,
+		"PATH=" + os.Getenv("PATH"),
+	}
+
+	var stdout, stderr bytes.Buffer
+	child.Stdout = &stdout
+	child.Stderr = &stderr
+
+	err := child.Run()
+	code := 0
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		code = exitErr.ExitCode()
+	} else if err != nil {
+		t.Fatalf("run %v: %v", args, err)
+	}
+
+	return stdout.String(), stderr.String(), code
+}
+
+// mustRun is run that fails the test when the command does not succeed.
+func (v *testVault) mustRun(t *testing.T, args ...string) string {
+	t.Helper()
+
+	stdout, stderr, code := v.run(t, args...)
+	if code != CODE_OK {
+		t.Fatalf("%v exited with %d: %s", args, code, stderr)
+	}
+
+	return stdout
+}
+
+func TestCommands(t *testing.T) {
+	vault := newTestVault(t)
+
+	vault.mustRun(t, "set", "-k", "db-pass", "--value", "one", "-t", "env=test")
+	vault.mustRun(t, "set", "-k", "api-key", "--value", "key")
+
+	t.Run("get", func(t *testing.T) {
+		var secret map[string]any
+		out := vault.mustRun(t, "get", "-k", "db-pass", "-o", "json")
+		if err := json.Unmarshal([]byte(out), &secret); err != nil {
+			t.Fatalf("get -o json output %q: %v", out, err)
+		}
+		if secret["key"] != "db-pass" || secret["value"] != "one" {
+			t.Errorf("get = %v, want db-pass with value one", secret)
+		}
+		tags, _ := secret["tags"].(map[string]any)
+		if tags["env"] != "test" {
+			t.Errorf("get tags = %v, want env=test", secret["tags"])
+		}
+
+		out = vault.mustRun(t, "get", vault.url+"/secrets/api-key", "-o", "yaml")
+		if !strings.Contains(out, "key: api-key") || !strings.Contains(out, "value: key") {
+			t.Errorf("get -o yaml = %q", out)
+		}
+	})
+
+	t.Run("get missing", func(t *testing.T) {
+		_, _, code := vault.run(t, "get", "-k", "missing")
+		if code != CODE_SECRET_NOT_FOUND {
+			t.Errorf("get missing exited with %d, want %d", code, CODE_SECRET_NOT_FOUND)
+		}
+	})
+
+	t.Run("ls", func(t *testing.T) {
+		out := vault.mustRun(t, "ls")
+		names := strings.Fields(out)
+		if len(names) != 2 || names[0] != "api-key" || names[1] != "db-pass" {
+			t.Errorf("ls = %q, want api-key and db-pass", out)
+		}
+
+		var list []map[string]any
+		out = vault.mustRun(t, "ls", "-s", "db-*", "-o", "json")
+		if err := json.Unmarshal([]byte(out), &list); err != nil {
+			t.Fatalf("ls -o json output %q: %v", out, err)
+		}
+		if len(list) != 1 || list[0]["key"] != "db-pass" {
+			t.Errorf("ls db-* = %v, want db-pass", list)
+		}
+	})
+
+	t.Run("resolve", func(t *testing.T) {
+		if out := vault.mustRun(t, "resolve", "-k", "db-pass"); out != "one\n" {
+			t.Errorf("resolve db-pass = %q, want %q", out, "one\n")
+		}
+
+		generated := strings.TrimSpace(vault.mustRun(t, "resolve", "-k", "new-pass", "--size", "24"))
+		if len(generated) != 24 {
+			t.Errorf("resolve generated %q, want 24 characters", generated)
+		}
+
+		again := strings.TrimSpace(vault.mustRun(t, "resolve", "-k", "new-pass"))
+		if again != generated {
+			t.Errorf("second resolve = %q, want the stored %q", again, generated)
+		}
+	})
+
+	t.Run("inject", func(t *testing.T) {
+		dir := t.TempDir()
+		input := filepath.Join(dir, "app.tmpl")
+		output := filepath.Join(dir, "app.conf")
+		template := `password={{ secret "` + vault.url + `/secrets/db-pass" }}`
+		if err := os.WriteFile(input, []byte(template), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		if out := vault.mustRun(t, "inject", "-i", input, "-o", output); strings.Contains(out, "one") {
+			t.Errorf("inject -o printed the secret to stdout: %q", out)
+		}
+
+		bits, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatalf("read output: %v", err)
+		}
+		if string(bits) != "password=one" {
+			t.Errorf("inject wrote %q, want %q", bits, "password=one")
+		}
+	})
+
+	t.Run("wrong token", func(t *testing.T) {
+		wrong := *vault
+		wrong.token = "other-token"
+		if _, _, code := wrong.run(t, "get", "-k", "db-pass"); code == CODE_OK {
+			t.Error("get with a wrong access token succeeded")
+		}
+	})
+}
+
+func TestWriteSecretFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not enforced on windows")
+	}
+
+	file := filepath.Join(t.TempDir(), "app.conf")
+	if err := os.WriteFile(file, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeSecretFile(file, []byte("secret")); err != nil {
+		t.Fatalf("writeSecretFile: %v", err)
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("mode = %o, want 600", mode)
+	}
+
+	bits, _ := os.ReadFile(file)
+	if string(bits) != "secret" {
+		t.Errorf("content = %q, want %q", bits, "secret")
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(filepath.Dir(file), ".app.conf.*"))
+	if len(matches) != 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+}
+
+func TestReadImportFileNumbers(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "secrets.json")
+	content := `{"count": 1000000, "id": 9007199254740993, "ratio": 0.5, "name": "x", "list": [1, 2]}`
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := readImportFile(importCmd, file, "")
+	if err != nil {
+		t.Fatalf("readImportFile: %v", err)
+	}
+
+	want := map[string]string{
+		"count": "1000000",
+		"id":    "9007199254740993",
+		"ratio": "0.5",
+		"name":  "x",
+		"list":  "[1,2]",
+	}
+	for k, v := range want {
+		if values[k] != v {
+			t.Errorf("%s = %q, want %q", k, values[k], v)
+		}
+	}
+}
+
+func TestSyncSkipsDisabledDestination(t *testing.T) {
+	src := newTestVault(t)
+	dst := newTestVault(t)
+
+	src.mustRun(t, "set", "-k", "db-pass", "--value", "new")
+	src.mustRun(t, "set", "-k", "api-key", "--value", "key")
+	dst.mustRun(t, "set", "-k", "db-pass", "--value", "old")
+	dst.mustRun(t, "update", "-k", "db-pass", "--disable")
+
+	stdout, stderr, code := src.run(t, "sync", "--from-endpoint", src.url, "--to-endpoint", dst.url)
+	if code != CODE_OK {
+		t.Fatalf("sync exited with %d: %s", code, stderr)
+	}
+	if !strings.Contains(stderr, "skipping db-pass") {
+		t.Errorf("sync stderr = %q, want a warning for db-pass", stderr)
+	}
+	if !strings.Contains(stdout, "1 created, 0 updated, 0 unchanged, 1 skipped") {
+		t.Errorf("sync summary = %q", stdout)
+	}
+
+	var list []map[string]any
+	out := dst.mustRun(t, "ls", "-o", "json")
+	if err := json.Unmarshal([]byte(out), &list); err != nil {
+		t.Fatalf("ls -o json output %q: %v", out, err)
+	}
+	for _, secret := range list {
+		if secret["key"] == "db-pass" && secret["enabled"] == true {
+			t.Error("sync enabled the disabled destination secret")
+		}
+	}
+	if out := dst.mustRun(t, "resolve", "-k", "api-key"); out != "key\n" {
+		t.Errorf("synced api-key = %q, want %q", out, "key\n")
+	}
+}
@@ -6,8 +6,10 @@ package cmd
 import (
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/hyprxlabs/go/dotenv"
+	"github.com/hyprxlabs/secrets-akv/akv"
 	"github.com/spf13/cobra"
 )
 
@@ -20,6 +22,15 @@ var configCmd = &cobra.Command{
 Configuration can be used to set environment variables for the Azure SDK
 such as AZURE_TENANT_ID, AZURE_CLIENT_ID, AZURE_CLIENT_SECRET, AZURE_IDENTITY.  
 
+The cloud key (HX_AKV_CLOUD) selects the Azure cloud used for vault names
+and authentication: public, china or usgov. The endpoint key (HX_AKV_ENDPOINT)
+overrides the vault url, for example to use a local emulator, and the
+ca-file key (HX_AKV_CA_FILE) adds trusted CA certificates for it. The
+rotate.hook key (HX_AKV_ROTATE_HOOK) is the command that 'rotate' and
+'resolve' run to propagate a new secret value before enabling it. Keys
+named profile.<name> (HX_AKV_PROFILE_<NAME>) hold generator profiles, see
+'generate'.
+
 AZURE_CLIENT_SECRET and AZURE_CLIENT_CERTIFICATE_PASSWORD will saved to the
 operating system secret store if available. Otherwise they will not be saved.
 
@@ -70,22 +81,7 @@ var configSetCmd = &cobra.Command{
 			}
 		}
 
-		envName := ""
-
-		switch name {
-		case "tenant", "AZURE_TENANT_ID":
-			envName = "AZURE_TENANT_ID"
-		case "identity", "AZURE_IDENTITY":
-			envName = "AZURE_IDENTITY"
-		case "client.id", "AZURE_CLIENT_ID":
-			envName = "AZURE_CLIENT_ID"
-		case "client.secret", "AZURE_CLIENT_SECRET":
-			envName = "AZURE_CLIENT_SECRET_KEY"
-		case "client.certificate.password", "AZURE_CLIENT_CERTIFICATE_PASSWORD":
-			envName = "AZURE_CLIENT_CERTIFICATE_PASSWORD_KEY"
-		case "client.certificate.path", "AZURE_CLIENT_CERTIFICATE_PATH":
-			envName = "AZURE_CLIENT_CERTIFICATE_PATH_KEY"
-		}
+		envName := configEnvName(name)
 
 		if envName == "" {
 			cmd.PrintErr("Configuration key is not valid: " + name + "\n")
@@ -159,22 +155,7 @@ var configGetCmd = &cobra.Command{
 			name = args[0]
 		}
 
-		envName := ""
-
-		switch name {
-		case "tenant", "AZURE_TENANT_ID":
-			envName = "AZURE_TENANT_ID"
-		case "identity", "AZURE_IDENTITY":
-			envName = "AZURE_IDENTITY"
-		case "client.id", "AZURE_CLIENT_ID":
-			envName = "AZURE_CLIENT_ID"
-		case "client.secret", "AZURE_CLIENT_SECRET":
-			envName = "AZURE_CLIENT_SECRET_KEY"
-		case "client.certificate.password", "AZURE_CLIENT_CERTIFICATE_PASSWORD":
-			envName = "AZURE_CLIENT_CERTIFICATE_PASSWORD_KEY"
-		case "client.certificate.path", "AZURE_CLIENT_CERTIFICATE_PATH":
-			envName = "AZURE_CLIENT_CERTIFICATE_PATH_KEY"
-		}
+		envName := configEnvName(name)
 
 		if envName == "" {
 			cmd.PrintErr("Configuration key is not valid: " + name + "\n")
@@ -226,22 +207,7 @@ var configRmCmd = &cobra.Command{
 			name = args[0]
 		}
 
-		envName := ""
-
-		switch name {
-		case "tenant", "AZURE_TENANT_ID":
-			envName = "AZURE_TENANT_ID"
-		case "identity", "AZURE_IDENTITY":
-			envName = "AZURE_IDENTITY"
-		case "client.id", "AZURE_CLIENT_ID":
-			envName = "AZURE_CLIENT_ID"
-		case "client.secret", "AZURE_CLIENT_SECRET":
-			envName = "AZURE_CLIENT_SECRET_KEY"
-		case "client.certificate.password", "AZURE_CLIENT_CERTIFICATE_PASSWORD":
-			envName = "AZURE_CLIENT_CERTIFICATE_PASSWORD_KEY"
-		case "client.certificate.path", "AZURE_CLIENT_CERTIFICATE_PATH":
-			envName = "AZURE_CLIENT_CERTIFICATE_PATH_KEY"
-		}
+		envName := configEnvName(name)
 
 		if envName == "" {
 			cmd.PrintErr("Configuration key is not valid: " + name + "\n")
@@ -293,6 +259,43 @@ var configRmCmd = &cobra.Command{
 	},
 }
 
+// configEnvName maps a configuration key to the variable stored in the
+// configuration env file. It returns an empty string for unknown keys.
+func configEnvName(name string) string {
+	switch name {
+	case "tenant", "AZURE_TENANT_ID":
+		return "AZURE_TENANT_ID"
+	case "identity", "AZURE_IDENTITY":
+		return "AZURE_IDENTITY"
+	case "client.id", "AZURE_CLIENT_ID":
+		return "AZURE_CLIENT_ID"
+	case "client.secret", "AZURE_CLIENT_SECRET":
+		return "AZURE_CLIENT_SECRET_KEY"
+	case "client.certificate.password", "AZURE_CLIENT_CERTIFICATE_PASSWORD":
+		return "AZURE_CLIENT_CERTIFICATE_PASSWORD_KEY"
+	case "client.certificate.path", "AZURE_CLIENT_CERTIFICATE_PATH":
+		return "AZURE_CLIENT_CERTIFICATE_PATH_KEY"
+	case "cloud", "HX_AKV_CLOUD":
+		return "HX_AKV_CLOUD"
+	case "endpoint", "HX_AKV_ENDPOINT":
+		return "HX_AKV_ENDPOINT"
+	case "ca-file", "HX_AKV_CA_FILE":
+		return "HX_AKV_CA_FILE"
+	case "rotate.hook", "HX_AKV_ROTATE_HOOK":
+		return "HX_AKV_ROTATE_HOOK"
+	}
+
+	if profile, ok := strings.CutPrefix(name, "profile."); ok && profile != "" {
+		return profileEnvPrefix + akv.VariableName(profile)
+	}
+
+	if strings.HasPrefix(name, profileEnvPrefix) && name != profileEnvPrefix {
+		return name
+	}
+
+	return ""
+}
+
 func init() {
 	configCmd.AddCommand(configSetCmd)
 	configCmd.AddCommand(configGetCmd)
@@ -0,0 +1,111 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"os"
+
+	"github.com/hyprxlabs/secrets-akv/akv"
+	"github.com/spf13/cobra"
+)
+
+// DeletedSecret is the JSON output of a soft deleted secret.
+type DeletedSecret struct {
+	Key              string            `json:"key"`
+	RecoveryID       string            `json:"recovery_id,omitempty"`
+	DeletedAt        string            `json:"deleted_at,omitempty"`
+	ScheduledPurgeAt string            `json:"scheduled_purge_at,omitempty"`
+	Enabled          bool              `json:"enabled"`
+	ExpiresAt        string            `json:"expires_at,omitempty"`
+	ContentType      string            `json:"content_type,omitempty"`
+	Tags             map[string]string `json:"tags,omitempty"`
+}
+
+// deletedCmd represents the deleted command
+var deletedCmd = &cobra.Command{
+	Use:   "deleted",
+	Short: "Works with soft deleted secrets",
+	Long: `Works with the soft deleted secrets of a vault. Deleted secrets can be
+restored with 'recover' until their scheduled purge date, or removed for
+good with 'purge'.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+		os.Exit(CODE_OK)
+	},
+}
+
+var deletedListCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   "Lists soft deleted secrets",
+	Long: `Lists the soft deleted secrets of a vault with their deletion date,
+scheduled purge date and recovery id.`,
+	Example: `hx-secrets-akv deleted ls --vault myvault
+hx-secrets-akv deleted ls akv://myvault/app-* --output json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		vaultName, _ := cmd.Flags().GetString("vault")
+		query, _ := cmd.Flags().GetString("query")
+		if len(args) > 0 {
+			ref := secretRef(cmd, args)
+			vaultName = ref.Vault
+			if ref.Name != "" {
+				query = ref.Name
+			}
+		}
+
+		if vaultName != "" {
+			if err := akv.ValidateVaultName(vaultName); err != nil {
+				exitWithError(cmd, err)
+			}
+		}
+
+		client := newClient(cmd, vaultName)
+
+		list, err := client.ListDeleted(cmd.Context(), query)
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+
+		deleted := make([]DeletedSecret, 0, len(list))
+		for _, item := range list {
+			deleted = append(deleted, DeletedSecret{
+				Key:              item.Name,
+				RecoveryID:       item.RecoveryID,
+				DeletedAt:        formatTime(item.DeletedDate),
+				ScheduledPurgeAt: formatTime(item.ScheduledPurgeDate),
+				Enabled:          item.Enabled,
+				ExpiresAt:        formatTime(item.Expires),
+				ContentType:      item.ContentType,
+				Tags:             item.Tags,
+			})
+		}
+
+		items := make([]any, len(deleted))
+		for i, d := range deleted {
+			items[i] = d
+		}
+
+		printOutput(cmd, "table", outputData{
+			Items: items,
+			Columns: []outputColumn{
+				{"NAME", func(i int) string { return deleted[i].Key }},
+				{"DELETED", func(i int) string { return deleted[i].DeletedAt }},
+				{"PURGE", func(i int) string { return deleted[i].ScheduledPurgeAt }},
+				{"RECOVERY ID", func(i int) string { return deleted[i].RecoveryID }},
+			},
+		})
+
+		os.Exit(CODE_OK)
+	},
+}
+
+func init() {
+	deletedListCmd.Flags().StringP("vault", "v", "", "The name of the Azure Key Vault")
+	deletedListCmd.Flags().StringP("query", "s", "", "A query to filter the secrets by name")
+	deletedListCmd.Flags().BoolP("interactive", "i", false, "Use interactive authentication")
+	deletedListCmd.Flags().Bool("device-code", false, "Use device code authentication")
+
+	deletedCmd.AddCommand(deletedListCmd)
+	rootCmd.AddCommand(deletedCmd)
+}
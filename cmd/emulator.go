@@ -0,0 +1,133 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/hyprxlabs/secrets-akv/emulator"
+	"github.com/spf13/cobra"
+)
+
+// emulatorCmd represents the emulator command
+var emulatorCmd = &cobra.Command{
+	Use:   "emulator",
+	Short: "Runs a local Azure Key Vault secrets emulator",
+	Long: `Runs a local server that implements the secrets part of the Azure Key Vault
+REST API (get, set, list, versions, delete, recover, purge, backup and restore).
+
+Secrets are stored in a JSON file, or in a bolt database when the file ends
+in .db or .bolt.
+
+The server uses https with a self-signed certificate unless --cert and --key
+are given. Use --cert-out to write the generated certificate to a file so
+that clients can trust it, for example with SSL_CERT_FILE.
+
+Requests without a bearer token get the authentication challenge the Azure
+SDK expects. Any token is accepted unless --token is set.`,
+	Example: `hx-secrets-akv emulator --file secrets.json
+hx-secrets-akv emulator --file secrets.db --addr 127.0.0.1:8443 --cert-out emulator.pem`,
+	Run: func(cmd *cobra.Command, args []string) {
+		file, _ := cmd.Flags().GetString("file")
+		addr, _ := cmd.Flags().GetString("addr")
+		certFile, _ := cmd.Flags().GetString("cert")
+		keyFile, _ := cmd.Flags().GetString("key")
+		certOut, _ := cmd.Flags().GetString("cert-out")
+		token, _ := cmd.Flags().GetString("token")
+		tenant, _ := cmd.Flags().GetString("tenant")
+		resource, _ := cmd.Flags().GetString("resource")
+
+		store, err := emulator.OpenStore(file)
+		if err != nil {
+			cmd.PrintErrf("Failed to open store %s: %v\n", file, err)
+			os.Exit(CODE_ERROR)
+		}
+		defer store.Close()
+
+		server := &http.Server{
+			Addr: addr,
+			Handler: emulator.NewServer(store, &emulator.Options{
+				Token:    token,
+				Tenant:   tenant,
+				Resource: resource,
+			}),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		// the azure sdk only sends bearer tokens over https, so there is no
+		// plain http mode.
+		if certFile != "" || keyFile != "" {
+			cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+			if err != nil {
+				cmd.PrintErrf("Failed to load certificate: %v\n", err)
+				os.Exit(CODE_ERROR)
+			}
+			server.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+		} else {
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil || host == "" {
+				host = "localhost"
+			}
+
+			cert, certPEM, err := emulator.SelfSignedCertificate([]string{host, "localhost", "127.0.0.1", "::1"})
+			if err != nil {
+				cmd.PrintErrf("Failed to create certificate: %v\n", err)
+				os.Exit(CODE_ERROR)
+			}
+
+			if certOut != "" {
+				if err := os.WriteFile(certOut, certPEM, 0644); err != nil {
+					cmd.PrintErrf("Failed to write certificate: %v\n", err)
+					os.Exit(CODE_ERROR)
+				}
+			}
+			server.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+		}
+
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			cmd.PrintErrf("Failed to listen on %s: %v\n", addr, err)
+			os.Exit(CODE_ERROR)
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			server.Shutdown(shutdownCtx)
+		}()
+
+		cmd.Printf("Key vault emulator listening on https://%s\n", listener.Addr().String())
+
+		err = server.ServeTLS(listener, "", "")
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			cmd.PrintErrf("Emulator failed: %v\n", err)
+			os.Exit(CODE_ERROR)
+		}
+	},
+}
+
+func init() {
+	emulatorCmd.Flags().StringP("file", "f", "akv-emulator.json", "JSON or bolt (.db, .bolt) file to store secrets in")
+	emulatorCmd.Flags().StringP("addr", "a", "127.0.0.1:8443", "Address to listen on")
+	emulatorCmd.Flags().String("cert", "", "TLS certificate file")
+	emulatorCmd.Flags().String("key", "", "TLS private key file")
+	emulatorCmd.Flags().String("cert-out", "", "Write the generated self-signed certificate to this file")
+	emulatorCmd.Flags().String("token", "", "Only accept this bearer token")
+	emulatorCmd.Flags().String("tenant", emulator.DefaultTenant, "Tenant advertised in the authentication challenge")
+	emulatorCmd.Flags().String("resource", emulator.DefaultResource, "Resource advertised in the authentication challenge")
+
+	rootCmd.AddCommand(emulatorCmd)
+}
@@ -0,0 +1,172 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/hyprxlabs/go/dotenv"
+	"github.com/hyprxlabs/secrets-akv/akv"
+	"github.com/spf13/cobra"
+)
+
+// execCmd represents the exec command
+var execCmd = &cobra.Command{
+	Use:   "exec [flags] -- <command> [args...]",
+	Short: "Runs a command with secrets injected as environment variables",
+	Long: `Runs a command with secrets from Azure Key Vault injected as environment
+variables.
+
+Variables are given with --env NAME=VALUE or read from dotenv files with
+--env-file. Values that are secret references (akv://, https:// vault urls or
+@Microsoft.KeyVault(...)) are replaced by the value of the secret; other
+values are passed as they are. --env wins over --env-file, and both win over
+the current environment unless --clean is set.
+
+Secrets are read concurrently before the command starts. Signals are
+forwarded to the command and its exit code is returned.`,
+	Example: `hx-secrets-akv exec --env DB_PASS=akv://myvault/db-pass -- ./server
+hx-secrets-akv exec --env-file secrets.env -- npm start`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		envs, _ := cmd.Flags().GetStringArray("env")
+		envFiles, _ := cmd.Flags().GetStringArray("env-file")
+		clean, _ := cmd.Flags().GetBool("clean")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+
+		values := map[string]string{}
+		for _, file := range envFiles {
+			if err := readEnvFile(file, values); err != nil {
+				cmd.PrintErrf("Failed to read env file %s: %v\n", file, err)
+				os.Exit(CODE_ERROR)
+			}
+		}
+
+		for _, e := range envs {
+			key, value, ok := strings.Cut(e, "=")
+			if !ok || key == "" {
+				cmd.PrintErrf("Invalid --env value %q, expected NAME=VALUE\n", e)
+				os.Exit(CODE_ERROR)
+			}
+			values[key] = value
+		}
+
+		resolver := newResolver(cmd)
+		resolver.SetConcurrency(concurrency)
+
+		resolved, err := resolver.ResolveAll(cmd.Context(), values)
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+
+		environ := []string{}
+		if !clean {
+			for _, e := range os.Environ() {
+				key, _, _ := strings.Cut(e, "=")
+				if _, ok := resolved[key]; !ok {
+					environ = append(environ, e)
+				}
+			}
+		}
+
+		for k, v := range resolved {
+			environ = append(environ, k+"="+v)
+		}
+
+		os.Exit(runCommand(cmd, args, environ))
+	},
+}
+
+// readEnvFile adds the variables of the dotenv file to values.
+func readEnvFile(file string, values map[string]string) error {
+	bits, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	doc, err := dotenv.Parse(string(bits))
+	if err != nil {
+		return err
+	}
+
+	for _, node := range doc.ToArray() {
+		if node.Type == dotenv.VARIABLE_TOKEN && node.Key != nil {
+			values[*node.Key] = node.Value
+		}
+	}
+
+	return nil
+}
+
+// runCommand starts the command with environ, forwards signals to it
+// until it exits and returns its exit code. Signals the terminal already
+// sent to the child's process group are not forwarded, so that ctrl-c
+// reaches it only once.
+func runCommand(cmd *cobra.Command, args []string, environ []string) int {
+	child := exec.Command(args[0], args[1:]...)
+	child.Env = environ
+	child.Stdin = os.Stdin
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardSignals...)
+
+	if err := child.Start(); err != nil {
+		signal.Stop(signals)
+		cmd.PrintErrf("Failed to start %s: %v\n", args[0], err)
+		return CODE_ERROR
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for sig := range signals {
+			if !terminalSignaled(child.Process.Pid, sig) {
+				child.Process.Signal(sig)
+			}
+		}
+	}()
+
+	err := child.Wait()
+	signal.Stop(signals)
+	close(signals)
+	<-done
+
+	if err == nil {
+		return CODE_OK
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if code := exitErr.ExitCode(); code >= 0 {
+			return code
+		}
+
+		// killed by a signal, report it the way shells do.
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			return 128 + int(status.Signal())
+		}
+	}
+
+	cmd.PrintErrf("Error: %v\n", err)
+	return CODE_ERROR
+}
+
+func init() {
+	execCmd.Flags().StringArrayP("env", "e", []string{}, "Environment variable as NAME=VALUE, where VALUE may be a secret reference")
+	execCmd.Flags().StringArray("env-file", []string{}, "Dotenv file with variables whose values may be secret references")
+	execCmd.Flags().Bool("clean", false, "Do not pass the current environment to the command")
+	execCmd.Flags().Int("concurrency", akv.DefaultConcurrency, "Number of secrets to read at the same time")
+	execCmd.Flags().BoolP("interactive", "i", false, "Use interactive authentication")
+	execCmd.Flags().Bool("device-code", false, "Use device code authentication")
+	execCmd.Flags().SetInterspersed(false)
+
+	rootCmd.AddCommand(execCmd)
+}
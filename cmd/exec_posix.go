@@ -0,0 +1,50 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// forwardSignals are the signals exec passes on to the child process.
+var forwardSignals = []os.Signal{
+	os.Interrupt,
+	syscall.SIGTERM,
+	syscall.SIGHUP,
+	syscall.SIGQUIT,
+	syscall.SIGUSR1,
+	syscall.SIGUSR2,
+}
+
+// terminalSignaled reports whether sig is one the terminal sends to its
+// foreground process group and the process pid is in that group, so that
+// it already got sig.
+func terminalSignaled(pid int, sig os.Signal) bool {
+	if sig != os.Interrupt && sig != syscall.SIGQUIT {
+		return false
+	}
+
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return false
+	}
+	defer tty.Close()
+
+	foreground, err := unix.IoctlGetInt(int(tty.Fd()), unix.TIOCGPGRP)
+	if err != nil {
+		return false
+	}
+
+	pgid, err := unix.Getpgid(pid)
+	return err == nil && pgid == foreground
+}
+
+// shellCommand runs command with the system shell.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "/bin/sh", "-c", command)
+}
@@ -0,0 +1,25 @@
+//go:build windows
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+)
+
+// forwardSignals are the signals exec passes on to the child process.
+var forwardSignals = []os.Signal{
+	os.Interrupt,
+}
+
+// terminalSignaled reports whether the child already got sig. The console
+// sends ctrl-c to every process attached to it, which includes the child.
+func terminalSignaled(pid int, sig os.Signal) bool {
+	return sig == os.Interrupt
+}
+
+// shellCommand runs command with the system shell.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "cmd.exe", "/C", command)
+}
@@ -0,0 +1,248 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hyprxlabs/secrets-akv/akv"
+	"github.com/mashiike/longduration"
+	"github.com/spf13/cobra"
+)
+
+// ExpiringSecret is the output of one secret of the expiry report.
+type ExpiringSecret struct {
+	Vault     string            `json:"vault"`
+	Key       string            `json:"key"`
+	Status    akv.ExpiryStatus  `json:"status"`
+	Enabled   bool              `json:"enabled"`
+	ExpiresAt string            `json:"expires_at,omitempty"`
+	ExpiresIn string            `json:"expires_in,omitempty"`
+	StartsAt  string            `json:"starts_at,omitempty"`
+	Tags      map[string]string `json:"tags,omitempty"`
+
+	props akv.SecretProperties
+}
+
+// expiringCmd represents the expiring command
+var expiringCmd = &cobra.Command{
+	Use:   "expiring [vault...]",
+	Short: "Reports expired and expiring secrets",
+	Long: `Scans one or more vaults and reports the secrets that are expired, that
+expire within --within (30d by default), that are not active yet because of
+their start date, or that have no expiry date. Use --all to also list the
+secrets that are fine, and --where to filter them as with 'ls'. Disabled
+secrets are skipped unless --include-disabled is set.
+
+The command exits with 13 when a reported secret is expired and with 22
+when one has any other status listed in --fail-on (expired and expiring by
+default), so it can fail a scheduled CI job. Use --fail-on none to always
+exit with 0.`,
+	Example: `hx-secrets-akv expiring --vault myvault
+hx-secrets-akv expiring --vault a --vault b --within 14d
+hx-secrets-akv expiring a b --fail-on expired,expiring,no-expiry --output json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		vaults, _ := cmd.Flags().GetStringArray("vault")
+		vaults = append(vaults, args...)
+
+		failOn, _ := cmd.Flags().GetStringSlice("fail-on")
+		thresholds := map[akv.ExpiryStatus]bool{}
+		for _, status := range failOn {
+			status = strings.ToLower(strings.TrimSpace(status))
+			if status == "none" || status == "" {
+				continue
+			}
+
+			if !slices.Contains(akv.ExpiryStatuses, akv.ExpiryStatus(status)) {
+				cmd.PrintErrf("Error: unknown --fail-on status %q\n", status)
+				os.Exit(CODE_ERROR)
+			}
+			thresholds[akv.ExpiryStatus(status)] = true
+		}
+
+		report := scanExpiry(cmd, vaults)
+
+		all, _ := cmd.Flags().GetBool("all")
+		if !all {
+			report = slices.DeleteFunc(report, func(s ExpiringSecret) bool {
+				return s.Status == akv.ExpiryOK
+			})
+		}
+
+		items := make([]any, len(report))
+		for i, s := range report {
+			items[i] = s
+		}
+
+		printOutput(cmd, "table", outputData{
+			Items: items,
+			Columns: []outputColumn{
+				{"VAULT", func(i int) string { return report[i].Vault }},
+				{"NAME", func(i int) string { return report[i].Key }},
+				{"STATUS", func(i int) string { return string(report[i].Status) }},
+				{"EXPIRES", func(i int) string { return report[i].ExpiresAt }},
+				{"IN", func(i int) string { return report[i].ExpiresIn }},
+				{"STARTS", func(i int) string { return report[i].StartsAt }},
+			},
+		})
+
+		code := CODE_OK
+		for _, s := range report {
+			if !thresholds[s.Status] {
+				continue
+			}
+
+			if s.Status == akv.ExpiryExpired {
+				code = CODE_SECRET_EXPIRED
+				break
+			}
+			code = CODE_SECRET_EXPIRING
+		}
+
+		os.Exit(code)
+	},
+}
+
+func init() {
+	expiringCmd.Flags().StringArrayP("vault", "v", nil, "The name of an Azure Key Vault to scan, can be repeated")
+	expiringCmd.Flags().StringP("query", "s", "", "A query to filter the secrets by name")
+	expiringCmd.Flags().StringP("where", "w", "", "A filter expression on the properties of the secrets")
+	expiringCmd.Flags().String("within", "30d", "Report secrets that expire within this duration")
+	expiringCmd.Flags().Bool("include-disabled", false, "Include disabled secrets")
+	expiringCmd.Flags().BoolP("all", "a", false, "Also list the secrets that are not expiring")
+	expiringCmd.Flags().StringSlice("fail-on", []string{"expired", "expiring"}, "Statuses that make the command exit with a non-zero code, or none")
+	expiringCmd.Flags().BoolP("interactive", "i", false, "Use interactive authentication")
+	expiringCmd.Flags().Bool("device-code", false, "Use device code authentication")
+
+	rootCmd.AddCommand(expiringCmd)
+}
+
+// scanExpiry lists the secrets of every vault and returns their expiry
+// status, most urgent first, using the query, where, within and
+// include-disabled flags of the command. It exits the process on failure.
+func scanExpiry(cmd *cobra.Command, vaults []string) []ExpiringSecret {
+	query, _ := cmd.Flags().GetString("query")
+	includeDisabled, _ := cmd.Flags().GetBool("include-disabled")
+
+	within := 30 * 24 * time.Hour
+	if value, _ := cmd.Flags().GetString("within"); value != "" {
+		dur, err := longduration.ParseDuration(value)
+		if err != nil {
+			cmd.PrintErrf("Error: invalid --within %q: %v\n", value, err)
+			os.Exit(CODE_ERROR)
+		}
+		within = dur
+	}
+
+	var filter *akv.Filter
+	if where, _ := cmd.Flags().GetString("where"); where != "" {
+		f, err := akv.ParseFilter(where)
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+		filter = f
+	}
+
+	if len(vaults) == 0 {
+		// a single vault behind --endpoint does not need a name.
+		vaults = []string{""}
+	}
+
+	// a vault given twice, or by name and by url, is scanned once so that
+	// reports have no duplicate entries.
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, vaultName := range vaults {
+		key := vaultName
+		if key != "" {
+			key = strings.ToLower(akv.VaultURL(vaultName))
+		}
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, vaultName)
+		}
+	}
+	vaults = unique
+
+	now := time.Now()
+	report := []ExpiringSecret{}
+	for _, vaultName := range vaults {
+		if vaultName != "" {
+			if err := akv.ValidateVaultName(vaultName); err != nil {
+				exitWithError(cmd, err)
+			}
+		}
+
+		client := newClient(cmd, vaultName)
+
+		list, err := client.List(cmd.Context(), query)
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+
+		for _, props := range list {
+			if !props.Enabled && !includeDisabled {
+				continue
+			}
+
+			if filter != nil && !filter.Match(&props, now) {
+				continue
+			}
+
+			s := ExpiringSecret{
+				Vault:     client.Vault(),
+				Key:       props.Name,
+				Status:    props.Expiry(now, within),
+				Enabled:   props.Enabled,
+				ExpiresAt: formatTime(props.Expires),
+				StartsAt:  formatTime(props.NotBefore),
+				Tags:      props.Tags,
+				props:     props,
+			}
+			if props.Expires != nil {
+				s.ExpiresIn = formatRemaining(props.Expires.Sub(now))
+			}
+
+			report = append(report, s)
+		}
+	}
+
+	slices.SortStableFunc(report, func(a, b ExpiringSecret) int {
+		urgency := slices.Index(akv.ExpiryStatuses, a.Status) - slices.Index(akv.ExpiryStatuses, b.Status)
+		if urgency != 0 {
+			return urgency
+		}
+
+		if c := compareTime(a.props.Expires, b.props.Expires); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.Vault+"/"+a.Key, b.Vault+"/"+b.Key)
+	})
+
+	return report
+}
+
+// formatRemaining formats a duration in whole days, hours or minutes,
+// negative for times in the past.
+func formatRemaining(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%s%dd", sign, d/(24*time.Hour))
+	case d >= time.Hour:
+		return fmt.Sprintf("%s%dh", sign, d/time.Hour)
+	default:
+		return fmt.Sprintf("%s%dm", sign, d/time.Minute)
+	}
+}
@@ -0,0 +1,192 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyprxlabs/go/dotenv"
+	"github.com/hyprxlabs/secrets-akv/akv"
+	"github.com/spf13/cobra"
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Exports the secrets of a vault as variables",
+	Long: `Exports the secrets of a vault, or the secrets matching --query, as
+variables in one of these formats:
+
+  env    dotenv file
+  json   JSON object
+  yaml   YAML mapping
+  sh     export statements for sh, bash and zsh
+  fish   set statements for fish
+  pwsh   $env: assignments for PowerShell
+
+Secret names are converted to variable names by upper casing them and
+replacing dashes with underscores (db-password becomes DB_PASSWORD). Use
+--map to pick a different name for a secret and --prefix to prefix every
+name.
+
+Disabled secrets are skipped because key vault does not return their
+values. Expired secrets are skipped unless --include-expired is set.`,
+	Example: `hx-secrets-akv export --vault myvault --format env
+hx-secrets-akv export --vault myvault --format json --query 'app-*'
+hx-secrets-akv export akv://myvault/app-* --map app-db-password=DATABASE_URL --format sh
+eval "$(hx-secrets-akv export --vault myvault --format sh)"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		vaultName, _ := cmd.Flags().GetString("vault")
+		query, _ := cmd.Flags().GetString("query")
+		format, _ := cmd.Flags().GetString("format")
+		mappings, _ := cmd.Flags().GetStringArray("map")
+		prefix, _ := cmd.Flags().GetString("prefix")
+		includeExpired, _ := cmd.Flags().GetBool("include-expired")
+		output, _ := cmd.Flags().GetString("output")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+
+		if len(args) > 0 {
+			ref := secretRef(cmd, args)
+			vaultName = ref.Vault
+			if ref.Name != "" {
+				query = ref.Name
+			}
+		}
+
+		if vaultName != "" {
+			if err := akv.ValidateVaultName(vaultName); err != nil {
+				exitWithError(cmd, err)
+			}
+		}
+
+		nameMap := map[string]string{}
+		for _, m := range mappings {
+			name, variable, ok := strings.Cut(m, "=")
+			if !ok || name == "" || variable == "" {
+				cmd.PrintErrf("Invalid --map value %q, expected secret-name=VARIABLE\n", m)
+				os.Exit(CODE_ERROR)
+			}
+			nameMap[strings.ToLower(name)] = variable
+		}
+
+		client := newClient(cmd, vaultName)
+
+		list, err := client.List(cmd.Context(), query)
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+
+		now := time.Now()
+		names := []string{}
+		for _, props := range list {
+			if !props.Enabled {
+				continue
+			}
+			if props.IsExpired(now) && !includeExpired {
+				continue
+			}
+			names = append(names, props.Name)
+		}
+
+		secrets, err := client.GetMany(cmd.Context(), names, concurrency)
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+
+		values := map[string]string{}
+		for _, secret := range secrets {
+			variable, ok := nameMap[strings.ToLower(secret.Name)]
+			if !ok {
+				variable = prefix + akv.VariableName(secret.Name)
+			}
+			values[variable] = secret.Value
+		}
+
+		content, err := formatVariables(format, values)
+		if err != nil {
+			cmd.PrintErrf("Error: %v\n", err)
+			os.Exit(CODE_ERROR)
+		}
+
+		if output == "" || output == "-" {
+			fmt.Fprint(cmd.OutOrStdout(), content)
+			return
+		}
+
+		if err := writeSecretFile(output, []byte(content)); err != nil {
+			cmd.PrintErrf("Failed to write %s: %v\n", output, err)
+			os.Exit(CODE_ERROR)
+		}
+	},
+}
+
+// formatVariables formats the variables, sorted by name, in one of the
+// export formats.
+func formatVariables(format string, values map[string]string) (string, error) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	switch strings.ToLower(format) {
+	case "env", "dotenv", "":
+		doc := dotenv.NewDocument()
+		for _, k := range keys {
+			doc.Set(k, values[k])
+		}
+		return doc.String(), nil
+	case "json":
+		bits, err := json.MarshalIndent(values, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(bits) + "\n", nil
+	case "yaml", "yml":
+		for _, k := range keys {
+			// JSON strings are valid double quoted YAML scalars.
+			key, _ := json.Marshal(k)
+			value, _ := json.Marshal(values[k])
+			fmt.Fprintf(&b, "%s: %s\n", key, value)
+		}
+	case "sh", "bash", "zsh":
+		for _, k := range keys {
+			fmt.Fprintf(&b, "export %s='%s'\n", k, strings.ReplaceAll(values[k], "'", `'\''`))
+		}
+	case "fish":
+		replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+		for _, k := range keys {
+			fmt.Fprintf(&b, "set -gx %s '%s'\n", k, replacer.Replace(values[k]))
+		}
+	case "pwsh", "powershell":
+		for _, k := range keys {
+			fmt.Fprintf(&b, "$env:%s = '%s'\n", k, strings.ReplaceAll(values[k], "'", "''"))
+		}
+	default:
+		return "", fmt.Errorf("unknown format %q", format)
+	}
+
+	return b.String(), nil
+}
+
+func init() {
+	exportCmd.Flags().StringP("vault", "v", "", "The name of the Azure Key Vault")
+	exportCmd.Flags().StringP("query", "s", "", "A query to filter the secrets by name")
+	exportCmd.Flags().StringP("format", "f", "env", "Output format: env, json, yaml, sh, fish or pwsh")
+	exportCmd.Flags().StringArray("map", []string{}, "Variable name for a secret as secret-name=VARIABLE")
+	exportCmd.Flags().String("prefix", "", "Prefix for the generated variable names")
+	exportCmd.Flags().Bool("include-expired", false, "Include expired secrets")
+	exportCmd.Flags().StringP("output", "o", "", "File to write the result to (default stdout)")
+	exportCmd.Flags().Int("concurrency", akv.DefaultConcurrency, "Number of secrets to read at the same time")
+	exportCmd.Flags().BoolP("interactive", "i", false, "Use interactive authentication")
+	exportCmd.Flags().Bool("device-code", false, "Use device code authentication")
+
+	rootCmd.AddCommand(exportCmd)
+}
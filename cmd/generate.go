@@ -0,0 +1,247 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hyprxlabs/go/env"
+	"github.com/hyprxlabs/secrets-akv/akv"
+	"github.com/spf13/cobra"
+)
+
+// profileEnvPrefix is the prefix of the configuration variables that hold
+// generator profiles.
+const profileEnvPrefix = "HX_AKV_PROFILE_"
+
+// generateCmd represents the generate command
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generates secret values",
+	Long: `Generates a random secret value and prints it, or stores it as a new version
+of a secret with --store.
+
+The value follows a generator profile, nist unless --profile is given,
+and the flags below override single settings of the profile. The built-in
+profiles are:
+
+  nist        16 characters with upper and lower case letters, digits and
+              special characters
+  sql-server  32 characters with at least 2 of each class, no ambiguous
+              characters and no characters that break connection strings
+  url-safe    32 letters, digits, - and _
+  hex64       64 hex digits
+
+Profiles are stored in the configuration as space separated options, which
+also replaces a built-in profile of the same name:
+
+  hx-secrets-akv config set profile.pin "size=6 chars=0123456789"
+  hx-secrets-akv config set profile.db "size=24 min-upper=2 min-digits=2 special=!#$% no-ambiguous"
+
+The options are size, chars, special, exclude, min-upper, min-lower,
+min-digits, min-special, and the flags upper, lower, digits, no-special and
+no-ambiguous. Use --list-profiles to print every profile.`,
+	Example: `hx-secrets-akv generate
+hx-secrets-akv generate --profile sql-server
+hx-secrets-akv generate --profile hex64 --count 3
+hx-secrets-akv generate --size 24 --min-digits 4 --no-ambiguous
+hx-secrets-akv generate --profile url-safe --store akv://myvault/api-key --expires-at 90d -t owner=api`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if list, _ := cmd.Flags().GetBool("list-profiles"); list {
+			profiles := generateProfiles()
+			names := make([]string, 0, len(profiles))
+			for name := range profiles {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				fmt.Fprintf(cmd.OutOrStdout(), "%-12s %s\n", name, profiles[name])
+			}
+			os.Exit(CODE_OK)
+		}
+
+		options, err := generateOptions(cmd, "nist")
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+
+		store, _ := cmd.Flags().GetString("store")
+		if store == "" {
+			count, _ := cmd.Flags().GetInt("count")
+			for range max(count, 1) {
+				value, err := akv.Generate(options)
+				if err != nil {
+					exitWithError(cmd, err)
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), value)
+			}
+			os.Exit(CODE_OK)
+		}
+
+		ref, err := akv.ParseEndpointRef(store, flagOrEnv(cmd, "endpoint", "HX_AKV_ENDPOINT"))
+		if err == nil {
+			err = ref.Validate()
+		}
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+
+		setOptions := &akv.SetOptions{}
+		setOptions.ContentType, _ = cmd.Flags().GetString("content-type")
+		if expiresAt, _ := cmd.Flags().GetString("expires-at"); expiresAt != "" {
+			setOptions.Expires = parseTime(expiresAt)
+			if setOptions.Expires == nil {
+				cmd.PrintErrf("Invalid --expires-at value %q\n", expiresAt)
+				os.Exit(CODE_ERROR)
+			}
+		}
+
+		if tags, _ := cmd.Flags().GetStringArray("tag"); len(tags) > 0 {
+			setOptions.Tags = parseTags(tags)
+		}
+
+		value, err := akv.Generate(options)
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+
+		client := newClient(cmd, ref.Vault)
+		secret, err := client.Set(cmd.Context(), ref.Name, value, setOptions)
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+
+		cmd.Println("Secret set successfully. version: " + secret.Version)
+		os.Exit(CODE_OK)
+	},
+}
+
+func init() {
+	generateCmd.Flags().StringP("profile", "p", "", "The generator profile to use")
+	generateCmd.Flags().Bool("list-profiles", false, "Print the generator profiles")
+	generateCmd.Flags().Int16P("size", "z", 0, "Size of the generated value")
+	generateCmd.Flags().BoolP("upper", "u", false, "Require at least one uppercase letter")
+	generateCmd.Flags().BoolP("lower", "l", false, "Require at least one lowercase letter")
+	generateCmd.Flags().BoolP("digits", "g", false, "Require at least one digit")
+	generateCmd.Flags().BoolP("no-special", "n", false, "Do not use special characters")
+	generateCmd.Flags().StringP("special", "s", "", "Special characters to use in the value")
+	generateCmd.Flags().StringP("chars", "c", "", "The only characters to use in the value")
+	generateCmd.Flags().Int("min-upper", 0, "Minimum number of uppercase letters")
+	generateCmd.Flags().Int("min-lower", 0, "Minimum number of lowercase letters")
+	generateCmd.Flags().Int("min-digits", 0, "Minimum number of digits")
+	generateCmd.Flags().Int("min-special", 0, "Minimum number of special characters")
+	generateCmd.Flags().StringP("exclude", "x", "", "Characters to never use")
+	generateCmd.Flags().Bool("no-ambiguous", false, "Do not use ambiguous characters such as I, l, 1, O and 0")
+	generateCmd.Flags().Int("count", 1, "Number of values to print")
+	generateCmd.Flags().String("store", "", "Store the value as a new version of this secret (akv:// or https url) instead of printing it")
+	generateCmd.Flags().StringP("expires-at", "e", "", "Expiration time of the stored secret (RFC3339 or duration format)")
+	generateCmd.Flags().String("content-type", "", "Content type of the stored secret")
+	generateCmd.Flags().StringArrayP("tag", "t", nil, "Tags for the stored secret in key=value format, can be repeated")
+	generateCmd.Flags().BoolP("interactive", "i", false, "Use interactive authentication")
+	generateCmd.Flags().Bool("device-code", false, "Use device code authentication")
+
+	rootCmd.AddCommand(generateCmd)
+}
+
+// generateProfiles returns the built-in profiles together with the profiles
+// of the configuration, formatted as options.
+func generateProfiles() map[string]string {
+	loadConfig()
+
+	profiles := map[string]string{}
+	for name, options := range akv.Profiles {
+		profiles[name] = options.String()
+	}
+
+	for _, entry := range os.Environ() {
+		key, value, _ := strings.Cut(entry, "=")
+		if name, ok := strings.CutPrefix(key, profileEnvPrefix); ok && name != "" {
+			profiles[profileName(name)] = value
+		}
+	}
+
+	return profiles
+}
+
+// generateProfile returns the options of a profile, looking in the
+// configuration before the built-in profiles.
+func generateProfile(name string) (akv.GenerateOptions, error) {
+	options, ok, err := lookupProfile(name)
+	if err == nil && !ok {
+		err = fmt.Errorf("%w: unknown profile %q", akv.ErrGenerateFailed, name)
+	}
+
+	return options, err
+}
+
+// lookupProfile is the akv.ProfileLookup of the profiles of the
+// configuration and the built-in profiles.
+func lookupProfile(name string) (akv.GenerateOptions, bool, error) {
+	loadConfig()
+
+	if spec := env.Get(profileEnvPrefix + akv.VariableName(name)); spec != "" {
+		options, err := akv.ParseGenerateOptions(spec)
+		return options, err == nil, err
+	}
+
+	return akv.BuiltinProfile(name)
+}
+
+// profileName turns the variable name part of a profile back into the
+// name used with --profile.
+func profileName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "_", "-")
+}
+
+// generateOptions returns the options of the --profile of the command, or
+// of def, with the generator flags the command has and that were set
+// applied on top.
+func generateOptions(cmd *cobra.Command, def string) (akv.GenerateOptions, error) {
+	name, _ := cmd.Flags().GetString("profile")
+	if name == "" {
+		name = def
+	}
+
+	options, err := generateProfile(name)
+	if err != nil {
+		return options, err
+	}
+
+	flags := cmd.Flags()
+	changed := func(name string) bool {
+		return flags.Lookup(name) != nil && flags.Changed(name)
+	}
+
+	if changed("size") {
+		options.Size, _ = flags.GetInt16("size")
+	}
+	for name, field := range map[string]*bool{"upper": &options.Upper, "lower": &options.Lower, "digits": &options.Digits, "no-special": &options.NoSpecial} {
+		if changed(name) {
+			*field, _ = flags.GetBool(name)
+		}
+	}
+	for name, field := range map[string]*string{"special": &options.Special, "chars": &options.Chars} {
+		if changed(name) {
+			*field, _ = flags.GetString(name)
+		}
+	}
+	for name, field := range map[string]*int{"min-upper": &options.MinUpper, "min-lower": &options.MinLower, "min-digits": &options.MinDigits, "min-special": &options.MinSpecial} {
+		if changed(name) {
+			*field, _ = flags.GetInt(name)
+		}
+	}
+	if changed("exclude") {
+		exclude, _ := flags.GetString("exclude")
+		options.Exclude += exclude
+	}
+	if noAmbiguous, _ := flags.GetBool("no-ambiguous"); changed("no-ambiguous") && noAmbiguous {
+		options.Exclude += akv.AmbiguousChars
+	}
+
+	return options, nil
+}
@@ -4,15 +4,12 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"context"
 	"encoding/json"
-	"errors"
-	"net/url"
+	"fmt"
 	"os"
-	"strings"
+	"time"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+	"github.com/hyprxlabs/secrets-akv/akv"
 	"github.com/spf13/cobra"
 )
 
@@ -32,159 +29,57 @@ var getCmd = &cobra.Command{
 	Use:   "get",
 	Short: "Gets the keyvault record from the secrets store as JSON",
 	Long: `Gets a secret value from azure key vault and prints it to stdout in JSON format.
+Use --output to print it as indented json, yaml, a table, tsv or a dotenv
+line instead, or --template to format it with a Go template.
 	
 The URL argument is optional. If provided, it should be in the format:
 https://<vault-name>.vault.azure.net/secrets/<key-name>/[<version>]
 akv://<vault-name>/<key-name>[/<version>]
+@Microsoft.KeyVault(SecretUri=https://<vault-name>.vault.azure.net/secrets/<key-name>/[<version>])
+@Microsoft.KeyVault(VaultName=<vault-name>;SecretName=<key-name>[;SecretVersion=<version>])
 
-If the URL is not provided, you must specify the vault and key using flags.`,
-	Run: func(cmd *cobra.Command, args []string) {
-
-		vaultName, _ := cmd.Flags().GetString("vault")
-		key, _ := cmd.Flags().GetString("key")
-		version, _ := cmd.Flags().GetString("version")
-		interactive, _ := cmd.Flags().GetBool("interactive")
-		deviceCode, _ := cmd.Flags().GetBool("device-code")
-		logDebug, _ = cmd.Flags().GetBool("debug")
-		ur := ""
-		if len(args) > 0 {
-			ur = args[0]
-		}
-
-		if len(ur) > 0 {
-			uri, err := url.Parse(ur)
-			if uri.Scheme != "https" && uri.Scheme != "akv" {
-				if logDebug {
-					cmd.PrintErrf("Invalid URL scheme: %s\n", uri.Scheme)
-				}
-				os.Exit(CODE_INVALID_URL)
-			}
-
-			if err != nil {
-				if logDebug {
-					cmd.PrintErrf("Invalid URL: %s\n", ur)
-				}
-				os.Exit(CODE_INVALID_URL)
-			}
-
-			vaultName = uri.Host
-			path := uri.Path
-			path = strings.TrimPrefix(path, "/")
-			path = strings.TrimSuffix(path, "/")
-			path = strings.TrimPrefix(path, "secrets/")
-
-			if len(path) > 0 {
-				if strings.ContainsRune(path, '/') {
-					parts := strings.Split(path, "/")
-					key = parts[0]
-					if len(parts) > 1 {
-						version = parts[1]
-					}
-				} else {
-					key = path
-				}
-			}
-		}
-
-		if vaultName == "" {
-			if !logDebug {
-				cmd.PrintErrf("Vault name is required. Use --vault <name> option or the URL argument.\n")
-			}
-			os.Exit(CODE_MISSING_VAULT_NAME)
-		}
-
-		if !strings.HasSuffix(vaultName, ".vault.azure.net") {
-			vaultName += ".vault.azure.net"
-		}
-
-		inter := ""
-		if deviceCode {
-			inter = "device-code"
-		}
-
-		if interactive {
-			inter = "interactive"
-		}
-
-		var interactivePtr *string
-		if inter != "" {
-			interactivePtr = &inter
-		}
-
-		creds, err := getCredential(interactivePtr, cmd.Context())
-		if err != nil {
-			if logDebug {
-				cmd.PrintErrf("Failed to get credentials: %v\n", err)
-			}
-			os.Exit(CODE_INVALID_CREDENTIALS)
-		}
-
-		client, err := azsecrets.NewClient("https://"+vaultName, creds, nil)
-		if err != nil {
-			if logDebug {
-				cmd.PrintErrf("Failed to create client: %v\n", err)
-			}
-			os.Exit(CODE_CLIENT_CREATION_FAILED)
-		}
+If the URL is not provided, you must specify the vault and key using flags.
 
-		resp, err := client.GetSecret(context.TODO(), key, version, nil)
-		var respErr *azcore.ResponseError
-		if err != nil || errors.As(err, &respErr) {
-			if respErr.ErrorCode == "SecretNotFound" {
-				if !logDebug {
-					cmd.PrintErrf("Secret not found: %s\n", key)
-				}
-				os.Exit(CODE_SECRET_NOT_FOUND)
-			} else {
-				if logDebug {
-					cmd.PrintErrf("Failed to get secret: %v\n", err)
-				}
-				os.Exit(CODE_SECRET_GET_FAILED)
-			}
-		}
+With --active the key names a dual secret and the slot tagged active=true,
+<key>-primary or <key>-secondary, is read instead, see 'rotate'.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		vaultName, key, version := secretArgs(cmd, args)
+		client := newClient(cmd, vaultName)
 
+		secret, err := readSecret(cmd, client, key, version)
 		if err != nil {
-			if logDebug {
-				cmd.PrintErrf("Failed to get secret: %v\n", err)
-			}
-			os.Exit(CODE_SECRET_NOT_FOUND)
-		}
-
-		expires := ""
-		if resp.Attributes.Expires != nil {
-			expires = resp.Attributes.Expires.Format("2006-01-02T15:04:05Z07:00")
-		}
-		startsAt := ""
-		if resp.Attributes.NotBefore != nil {
-			startsAt = resp.Attributes.NotBefore.Format("2006-01-02T15:04:05Z07:00")
-		}
-
-		contentType := ""
-		if resp.ContentType != nil {
-			contentType = *resp.ContentType
-		}
-
-		secret := Secret{
-			Key:         resp.ID.Name(),
-			Value:       *resp.Value,
-			ContentType: contentType,
-			Tags:        resp.Tags,
-			Enabled:     *resp.Attributes.Enabled,
-			Version:     resp.ID.Version(),
-			ExpiresAt:   expires,
-			StartsAt:    startsAt,
+			exitWithError(cmd, err)
 		}
 
-		bytes, err := json.Marshal(secret)
-		if err != nil {
-			if logDebug {
+		if outputFormat(cmd, "") == "" {
+			bytes, err := json.Marshal(newSecretOutput(secret))
+			if err != nil {
 				cmd.PrintErrf("Failed to marshal secret: %v\n", err)
+				os.Exit(CODE_SECRET_GET_FAILED)
 			}
-			os.Exit(CODE_SECRET_GET_FAILED)
-		}
 
-		cmd.OutOrStdout().Write(bytes)
-		cmd.OutOrStdout().Write([]byte("\n"))
+			cmd.OutOrStdout().Write(bytes)
+			cmd.OutOrStdout().Write([]byte("\n"))
+			return
+		}
+
+		output := newSecretOutput(secret)
+		printOutput(cmd, "json", outputData{
+			Items:  []any{output},
+			Single: true,
+			Columns: []outputColumn{
+				{"KEY", func(int) string { return output.Key }},
+				{"VALUE", func(int) string { return output.Value }},
+				{"VERSION", func(int) string { return output.Version }},
+				{"ENABLED", func(int) string { return fmt.Sprint(output.Enabled) }},
+				{"EXPIRES", func(int) string { return output.ExpiresAt }},
+				{"CONTENT TYPE", func(int) string { return output.ContentType }},
+				{"TAGS", func(int) string { return formatTags(secret.Tags) }},
+			},
+			Env: func(int) (string, string) {
+				return akv.VariableName(secret.Name), secret.Value
+			},
+		})
 	},
 }
 
@@ -196,6 +91,8 @@ var getValueCmd = &cobra.Command{
 The URL argument is optional. If provided, it should be in the format:
 https://<vault-name>.vault.azure.net/secrets/<key-name>/[<version>]
 akv://<vault-name>/<key-name>[/<version>]
+@Microsoft.KeyVault(SecretUri=https://<vault-name>.vault.azure.net/secrets/<key-name>/[<version>])
+@Microsoft.KeyVault(VaultName=<vault-name>;SecretName=<key-name>[;SecretVersion=<version>])
 
 If the URL is not provided, you must specify the vault and key using flags.
 	`,
@@ -205,119 +102,68 @@ hx-secrets-akv get value akv://myvault/mykey
 	`,
 	Args: cobra.RangeArgs(0, 1),
 	Run: func(cmd *cobra.Command, args []string) {
+		vaultName, key, version := secretArgs(cmd, args)
+		client := newClient(cmd, vaultName)
 
-		vaultName, _ := cmd.Flags().GetString("vault")
-		key, _ := cmd.Flags().GetString("key")
-		version, _ := cmd.Flags().GetString("version")
-		interactive, _ := cmd.Flags().GetBool("interactive")
-		deviceCode, _ := cmd.Flags().GetBool("device-code")
-		logDebug, _ := cmd.Flags().GetBool("debug")
-		ur := ""
-		if len(args) > 0 {
-			ur = args[0]
-		}
-
-		if len(ur) > 0 {
-			uri, err := url.Parse(ur)
-			if err != nil {
-				if logDebug {
-					cmd.PrintErrf("Invalid URL: %s\n", ur)
-				}
-				os.Exit(CODE_INVALID_URL)
-			}
-
-			vaultName = uri.Host
-			path := uri.Path
-			path = strings.TrimPrefix(path, "/")
-			path = strings.TrimSuffix(path, "/")
-			path = strings.TrimPrefix(path, "secrets/")
-			if len(path) > 0 {
-				if strings.ContainsRune(path, '/') {
-					parts := strings.SplitN(path, "/", 2)
-					key = parts[0]
-					if len(parts) > 1 {
-						version = parts[1]
-					}
-				} else {
-					key = path
-				}
-			}
-		}
-
-		if vaultName == "" {
-			if logDebug {
-				cmd.PrintErrf("Vault name is required\n")
-			}
-
-			os.Exit(CODE_MISSING_VAULT_NAME)
-		}
-
-		if key == "" {
-			if logDebug {
-				cmd.PrintErrf("Key name is required\n")
-			}
-			os.Exit(CODE_MISSING_VAULT_SECRET_NAME)
-		}
-
-		if !strings.HasSuffix(vaultName, ".vault.azure.net") {
-			vaultName += ".vault.azure.net"
-		}
-
-		inter := ""
-		if deviceCode {
-			inter = "device-code"
-		}
-
-		if interactive {
-			inter = "interactive"
+		secret, err := readSecret(cmd, client, key, version)
+		if err != nil {
+			exitWithError(cmd, err)
 		}
 
-		var interactivePtr *string
-		if inter != "" {
-			interactivePtr = &inter
-		}
+		fmt.Fprintln(cmd.OutOrStdout(), secret.Value)
+		os.Exit(CODE_OK)
+	},
+}
 
-		creds, err := getCredential(interactivePtr, cmd.Context())
-		if err != nil {
-			if logDebug {
-				cmd.PrintErrf("Failed to get credentials: %v\n", err)
-			}
-			os.Exit(CODE_INVALID_CREDENTIALS)
+// readSecret reads a version of a secret, or the active slot of a dual
+// secret with --active.
+func readSecret(cmd *cobra.Command, client *akv.Client, key, version string) (*akv.Secret, error) {
+	if active, _ := cmd.Flags().GetBool("active"); active {
+		if version != "" {
+			return nil, fmt.Errorf("%w: --active reads the latest version of the active slot and cannot be combined with version %s", akv.ErrInvalidURL, version)
 		}
 
-		client, err := azsecrets.NewClient("https://"+vaultName, creds, nil)
+		d, err := client.GetDual(cmd.Context(), key)
 		if err != nil {
-			if logDebug {
-				cmd.PrintErrf("Failed to create client: %v\n", err)
-			}
-			os.Exit(CODE_CLIENT_CREATION_FAILED)
+			return nil, err
 		}
+		return d.Active(), nil
+	}
 
-		resp, err := client.GetSecret(context.TODO(), key, version, nil)
-		var respErr *azcore.ResponseError
-		if err != nil || errors.As(err, &respErr) {
-			if respErr.ErrorCode == "SecretNotFound" {
-				if !logDebug {
-					cmd.PrintErrf("Secret not found: %s\n", key)
-				}
-				os.Exit(CODE_SECRET_NOT_FOUND)
-			} else {
-				if logDebug {
-					cmd.PrintErrf("Failed to get secret: %v\n", err)
-				}
-				os.Exit(CODE_SECRET_GET_FAILED)
-			}
-		}
+	return client.Get(cmd.Context(), key, version)
+}
 
-		if err != nil {
-			if logDebug {
-				cmd.PrintErrf("Failed to get secret: %v\n", err)
-			}
-			os.Exit(CODE_SECRET_GET_FAILED)
-		}
-		cmd.Println(*resp.Value)
-		os.Exit(0)
-	},
+// newSecretOutput converts a secret to the JSON shape printed by get.
+func newSecretOutput(secret *akv.Secret) Secret {
+	expires := ""
+	if secret.Expires != nil {
+		expires = secret.Expires.Format(time.RFC3339)
+	}
+
+	startsAt := ""
+	if secret.NotBefore != nil {
+		startsAt = secret.NotBefore.Format(time.RFC3339)
+	}
+
+	var tags map[string]*string
+	if secret.Tags != nil {
+		tags = make(map[string]*string, len(secret.Tags))
+		for k, v := range secret.Tags {
+			value := v
+			tags[k] = &value
+		}
+	}
+
+	return Secret{
+		Key:         secret.Name,
+		Value:       secret.Value,
+		ContentType: secret.ContentType,
+		Tags:        tags,
+		Enabled:     secret.Enabled,
+		Version:     secret.Version,
+		ExpiresAt:   expires,
+		StartsAt:    startsAt,
+	}
 }
 
 func init() {
@@ -327,6 +173,7 @@ func init() {
 	getValueCmd.Flags().BoolP("interactive", "i", false, "Use interactive authentication")
 	getValueCmd.Flags().Bool("device-code", false, "Use device code authentication")
 	getValueCmd.Flags().BoolP("quiet", "q", false, "Suppress output messages")
+	getValueCmd.Flags().Bool("active", false, "Read the active slot of a dual secret")
 
 	getCmd.Flags().StringP("vault", "v", "", "Key Vault name (e.g., myvault)")
 	getCmd.Flags().StringP("key", "k", "", "Key name in the Key Vault")
@@ -334,6 +181,7 @@ func init() {
 	getCmd.Flags().BoolP("interactive", "i", false, "Use interactive authentication")
 	getCmd.Flags().Bool("device-code", false, "Use device code authentication")
 	getCmd.Flags().BoolP("quiet", "q", false, "Suppress output messages")
+	getCmd.Flags().Bool("active", false, "Read the active slot of a dual secret")
 	getCmd.AddCommand(getValueCmd)
 
 	rootCmd.AddCommand(getCmd)
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hyprxlabs/secrets-akv/akv"
+	"github.com/spf13/cobra"
+)
+
+// addHookFlags adds the rotation hook flags to a command.
+func addHookFlags(cmd *cobra.Command) {
+	cmd.Flags().String("hook", "", "A shell command that receives rotated values on stdin before they are enabled (default $HX_AKV_ROTATE_HOOK)")
+	cmd.Flags().Duration("hook-timeout", 5*time.Minute, "The time the rotation hook may run")
+	cmd.Flags().Bool("no-hook", false, "Do not run the configured rotation hook")
+}
+
+// rotationHook returns the rotation hook set with --hook or the rotate.hook
+// configuration for secrets of vault, or nil when there is none.
+//
+// The hook runs with the system shell. The new value is written to its
+// stdin, never to its arguments, and HX_AKV_VAULT, HX_AKV_SECRET_NAME,
+// HX_AKV_SECRET_VERSION and HX_AKV_PREVIOUS_VERSION describe the secret.
+// Its output goes to stderr so that it does not mix with the output of the
+// command. A non-zero exit code fails the rotation.
+func rotationHook(cmd *cobra.Command, vault string) akv.RotationHook {
+	if noHook, _ := cmd.Flags().GetBool("no-hook"); noHook {
+		return nil
+	}
+
+	command := strings.TrimSpace(flagOrEnv(cmd, "hook", "HX_AKV_ROTATE_HOOK"))
+	if command == "" {
+		return nil
+	}
+
+	timeout, _ := cmd.Flags().GetDuration("hook-timeout")
+
+	return func(ctx context.Context, previous, next *akv.Secret) error {
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		hook := shellCommand(ctx, command)
+		hook.Stdin = strings.NewReader(next.Value)
+		hook.Stdout = cmd.ErrOrStderr()
+		hook.Stderr = cmd.ErrOrStderr()
+		hook.Env = append(os.Environ(),
+			"HX_AKV_VAULT="+vault,
+			"HX_AKV_SECRET_NAME="+next.Name,
+			"HX_AKV_SECRET_VERSION="+next.Version,
+			"HX_AKV_PREVIOUS_VERSION="+previous.Version,
+		)
+
+		if err := hook.Run(); err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return fmt.Errorf("timed out after %s", timeout)
+			}
+			return err
+		}
+
+		return nil
+	}
+}
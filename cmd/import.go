@@ -0,0 +1,241 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hyprxlabs/go/dotenv"
+	"github.com/hyprxlabs/secrets-akv/akv"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Imports secrets from a dotenv, JSON or YAML file",
+	Long: `Imports the key value pairs of a dotenv, JSON or YAML file as secrets.
+
+The format is taken from the file extension (.env, .json, .yaml or .yml)
+unless --format is set; use - as the file to read from stdin. JSON and YAML
+files must hold a single object. Values that are not strings are stored as
+JSON.
+
+Keys are converted to secret names by lower casing them and replacing
+characters key vault does not allow with dashes (DB_PASSWORD becomes
+db-password).
+
+Secrets whose current value is the same are not updated unless --force is
+set. Use --dry-run to see what would be created, updated or left unchanged.`,
+	Example: `hx-secrets-akv import --vault myvault secrets.env
+hx-secrets-akv import --vault myvault secrets.json --tags env=prod,team=api --expires-at 90d
+hx-secrets-akv import --vault myvault secrets.yaml --dry-run`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		vaultName, _ := cmd.Flags().GetString("vault")
+		format, _ := cmd.Flags().GetString("format")
+		tags, _ := cmd.Flags().GetStringArray("tags")
+		expiresAt, _ := cmd.Flags().GetString("expires-at")
+		force, _ := cmd.Flags().GetBool("force")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if vaultName != "" {
+			if err := akv.ValidateVaultName(vaultName); err != nil {
+				exitWithError(cmd, err)
+			}
+		}
+
+		values, err := readImportFile(cmd, args[0], format)
+		if err != nil {
+			cmd.PrintErrf("Failed to read %s: %v\n", args[0], err)
+			os.Exit(CODE_ERROR)
+		}
+
+		secrets := map[string]string{}
+		keys := map[string]string{}
+		for key, value := range values {
+			name := akv.SecretName(key)
+			if name == "" {
+				cmd.PrintErrf("Key %q has no characters allowed in a secret name\n", key)
+				os.Exit(CODE_INVALID_URL)
+			}
+
+			if other, ok := keys[name]; ok {
+				cmd.PrintErrf("Keys %q and %q both map to the secret name %q\n", other, key, name)
+				os.Exit(CODE_ERROR)
+			}
+
+			keys[name] = key
+			secrets[name] = value
+		}
+
+		names := make([]string, 0, len(secrets))
+		for name := range secrets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		options := &akv.SetOptions{}
+		if len(tags) > 0 {
+			options.Tags = parseTags(tags)
+		}
+
+		if expiresAt != "" {
+			options.Expires = parseTime(expiresAt)
+			if options.Expires == nil {
+				cmd.PrintErrf("Invalid --expires-at value %q\n", expiresAt)
+				os.Exit(CODE_ERROR)
+			}
+		}
+
+		client := newClient(cmd, vaultName)
+
+		created, updated, unchanged := 0, 0, 0
+		for _, name := range names {
+			action := "create"
+			current, err := client.Get(cmd.Context(), name, "")
+			if err == nil {
+				action = "update"
+				if current.Value == secrets[name] && !force {
+					action = "unchanged"
+				}
+			} else if !akv.IsNotFound(err) {
+				exitWithError(cmd, err)
+			}
+
+			switch action {
+			case "create":
+				created++
+			case "update":
+				updated++
+			default:
+				unchanged++
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%-9s %s\n", action, name)
+			if dryRun || action == "unchanged" {
+				continue
+			}
+
+			if _, err := client.Set(cmd.Context(), name, secrets[name], options); err != nil {
+				exitWithError(cmd, err)
+			}
+		}
+
+		summary := fmt.Sprintf("%d created, %d updated, %d unchanged", created, updated, unchanged)
+		if dryRun {
+			summary = "dry run: " + summary
+		}
+
+		fmt.Fprintln(cmd.OutOrStdout(), summary)
+		os.Exit(CODE_OK)
+	},
+}
+
+// readImportFile reads the key value pairs of a dotenv, JSON or YAML file.
+func readImportFile(cmd *cobra.Command, file string, format string) (map[string]string, error) {
+	var bits []byte
+	var err error
+	if file == "-" {
+		bits, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		bits, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".json":
+			format = "json"
+		case ".yaml", ".yml":
+			format = "yaml"
+		default:
+			format = "env"
+		}
+	}
+
+	values := map[string]string{}
+	switch strings.ToLower(format) {
+	case "env", "dotenv":
+		doc, err := dotenv.Parse(string(bits))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, node := range doc.ToArray() {
+			if node.Type == dotenv.VARIABLE_TOKEN && node.Key != nil {
+				values[*node.Key] = node.Value
+			}
+		}
+
+		return values, nil
+	case "json":
+		// numbers are kept as written instead of going through float64,
+		// which would turn 1000000 into 1e+06 and round large integers.
+		data := map[string]any{}
+		decoder := json.NewDecoder(bytes.NewReader(bits))
+		decoder.UseNumber()
+		if err := decoder.Decode(&data); err != nil {
+			return nil, err
+		}
+
+		return stringValues(data)
+	case "yaml", "yml":
+		data := map[string]any{}
+		if err := yaml.Unmarshal(bits, &data); err != nil {
+			return nil, err
+		}
+
+		return stringValues(data)
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// stringValues converts the values of a decoded object to strings. Strings
+// are kept as they are and other values are encoded as JSON.
+func stringValues(data map[string]any) (map[string]string, error) {
+	values := make(map[string]string, len(data))
+	for k, v := range data {
+		switch s := v.(type) {
+		case string:
+			values[k] = s
+			continue
+		case json.Number:
+			values[k] = s.String()
+			continue
+		}
+
+		bits, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("value of %s: %w", k, err)
+		}
+		values[k] = string(bits)
+	}
+
+	return values, nil
+}
+
+func init() {
+	importCmd.Flags().StringP("vault", "v", "", "The name of the Azure Key Vault")
+	importCmd.Flags().StringP("format", "f", "", "Input format: env, json or yaml (default from the file extension)")
+	importCmd.Flags().StringArrayP("tags", "t", []string{}, "Tags for the imported secrets as key=value, comma separated or repeated")
+	importCmd.Flags().StringP("expires-at", "e", "", "Expiration time of the imported secrets (RFC3339 or duration format)")
+	importCmd.Flags().Bool("force", false, "Set secrets even when their value is unchanged")
+	importCmd.Flags().Bool("dry-run", false, "Only show what would be created, updated or left unchanged")
+	importCmd.Flags().BoolP("interactive", "i", false, "Use interactive authentication")
+	importCmd.Flags().Bool("device-code", false, "Use device code authentication")
+
+	rootCmd.AddCommand(importCmd)
+}
@@ -0,0 +1,181 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/hyprxlabs/secrets-akv/akv"
+	"github.com/spf13/cobra"
+)
+
+// injectCmd represents the inject command
+var injectCmd = &cobra.Command{
+	Use:   "inject",
+	Short: "Renders a Go template with secrets from Azure Key Vault",
+	Long: `Renders a file with Go text/template syntax and the following functions:
+
+  secret "<ref>"                 the value of the secret
+  secretJSON "<ref>" "<path>"    a field of a secret that holds JSON, where path
+                                 is a dot separated list of keys or array indexes
+  generate <size>                a new random password
+
+References use any of the forms understood by get (akv://, https:// or
+@Microsoft.KeyVault(...)). Each reference is read once per run.
+
+The input is read from --input or stdin and the result is written to
+--output with 0600 permissions, or to stdout.`,
+	Example: `hx-secrets-akv inject -i app.tmpl -o app.conf
+echo 'password={{ secret "akv://myvault/db-pass" }}' | hx-secrets-akv inject`,
+	Run: func(cmd *cobra.Command, args []string) {
+		input, _ := cmd.Flags().GetString("input")
+		output, _ := cmd.Flags().GetString("output")
+
+		var bits []byte
+		var err error
+		if input == "" || input == "-" {
+			bits, err = io.ReadAll(cmd.InOrStdin())
+		} else {
+			bits, err = os.ReadFile(input)
+		}
+		if err != nil {
+			cmd.PrintErrf("Failed to read template: %v\n", err)
+			os.Exit(CODE_ERROR)
+		}
+
+		name := input
+		if name == "" {
+			name = "stdin"
+		}
+
+		var resolver *akv.Resolver
+		tmpl, err := template.New(name).
+			Option("missingkey=error").
+			Funcs(templateFuncs(cmd.Context(), func() *akv.Resolver {
+				if resolver == nil {
+					resolver = newResolver(cmd)
+				}
+				return resolver
+			})).
+			Parse(string(bits))
+		if err != nil {
+			cmd.PrintErrf("Failed to parse template: %v\n", err)
+			os.Exit(CODE_ERROR)
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, nil); err != nil {
+			var akvErr *akv.Error
+			if errors.As(err, &akvErr) {
+				exitWithError(cmd, akvErr)
+			}
+			cmd.PrintErrf("Failed to render template: %v\n", err)
+			os.Exit(CODE_ERROR)
+		}
+
+		if output == "" || output == "-" {
+			cmd.OutOrStdout().Write(buf.Bytes())
+			return
+		}
+
+		if err := writeSecretFile(output, buf.Bytes()); err != nil {
+			cmd.PrintErrf("Failed to write %s: %v\n", output, err)
+			os.Exit(CODE_ERROR)
+		}
+	},
+}
+
+// templateFuncs returns the secret, secretJSON and generate template
+// functions. The resolver is only created when a secret is first read so
+// that templates without secrets do not need credentials.
+func templateFuncs(ctx context.Context, resolver func() *akv.Resolver) template.FuncMap {
+	cache := map[string]string{}
+	secret := func(ref string) (string, error) {
+		if value, ok := cache[ref]; ok {
+			return value, nil
+		}
+
+		value, err := resolver().Value(ctx, ref)
+		if err != nil {
+			return "", err
+		}
+
+		cache[ref] = value
+		return value, nil
+	}
+
+	return template.FuncMap{
+		"secret": secret,
+		"secretJSON": func(ref string, path string) (string, error) {
+			value, err := secret(ref)
+			if err != nil {
+				return "", err
+			}
+
+			return jsonField(value, path)
+		},
+		"generate": func(size int) (string, error) {
+			return akv.Generate(akv.GenerateOptions{Size: int16(size)})
+		},
+	}
+}
+
+// jsonField returns the field at the dot separated path of the JSON
+// document. Strings are returned as they are, other values as JSON.
+func jsonField(document string, path string) (string, error) {
+	var value any
+	if err := json.Unmarshal([]byte(document), &value); err != nil {
+		return "", fmt.Errorf("secret is not valid JSON: %w", err)
+	}
+
+	if path != "" {
+		for _, part := range strings.Split(path, ".") {
+			switch v := value.(type) {
+			case map[string]any:
+				field, ok := v[part]
+				if !ok {
+					return "", fmt.Errorf("field %q not found in %q", part, path)
+				}
+				value = field
+			case []any:
+				i, err := strconv.Atoi(part)
+				if err != nil || i < 0 || i >= len(v) {
+					return "", fmt.Errorf("index %q out of range in %q", part, path)
+				}
+				value = v[i]
+			default:
+				return "", fmt.Errorf("field %q not found in %q", part, path)
+			}
+		}
+	}
+
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+
+	bits, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	return string(bits), nil
+}
+
+func init() {
+	injectCmd.Flags().StringP("input", "i", "", "Template file to render (default stdin)")
+	injectCmd.Flags().StringP("output", "o", "", "File to write the result to (default stdout)")
+	injectCmd.Flags().Bool("interactive", false, "Use interactive authentication")
+	injectCmd.Flags().Bool("device-code", false, "Use device code authentication")
+
+	rootCmd.AddCommand(injectCmd)
+}
@@ -4,12 +4,14 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"net/url"
+	"fmt"
 	"os"
-	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+	"github.com/hyprxlabs/secrets-akv/akv"
 	"github.com/spf13/cobra"
 )
 
@@ -17,115 +19,136 @@ import (
 var listCmd = &cobra.Command{
 	Use:     "ls",
 	Aliases: []string{"list"},
-	Short:   "A brief description of your command",
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
-
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+	Short:   "Lists the secrets of a vault",
+	Long: `Lists the names of the secrets of a vault, or of the secrets matching
+--query.
+
+Use --long or --output to print their properties (enabled, expiry, update
+time, content type and tags) instead; values are never read. The env
+output prints a reference to each secret, ready for exec or render-env.
+
+--where filters on the properties with an expression. Fields are name,
+version, content_type, tags.<key> (or tags["<key>"]), enabled, managed,
+expires, not_before, created and updated. Times compare with now, now+30d,
+now-12h, null or a quoted RFC3339 time or date. Operators are ==, !=, <,
+<=, >, >=, =~ (regular expression), !, && and ||. --regex filters the names
+with a regular expression. --sort orders by name, created, updated or
+expires (secrets without an expiry last) and --limit keeps the first n.`,
 	Example: `hx-secrets-akv ls --vault myvault
 hx-secrets-akv ls --vault myvault --query mysecret*
 hx-secrets-akv ls https://myvault.vault.azure.net/secrets/mysecret*
-hx-secrets-akv ls akv://myvault/mysecret*`,
+hx-secrets-akv ls akv://myvault/mysecret*
+hx-secrets-akv ls --vault myvault -l
+hx-secrets-akv ls --vault myvault --output json
+hx-secrets-akv ls --vault myvault --where 'tags.env == "prod" && expires < now+30d && enabled'
+hx-secrets-akv ls --vault myvault --regex '^db-' --sort updated --reverse --limit 10`,
 	Run: func(cmd *cobra.Command, args []string) {
 		vaultName, _ := cmd.Flags().GetString("vault")
 		query, _ := cmd.Flags().GetString("query")
-		interactive, _ := cmd.Flags().GetBool("interactive")
-		deviceCode, _ := cmd.Flags().GetBool("device-code")
-		logDebug, _ = cmd.Flags().GetBool("debug")
-		ur := ""
 		if len(args) > 0 {
-			ur = args[0]
+			// the name part of the reference is used as the query.
+			ref := secretRef(cmd, args)
+			vaultName = ref.Vault
+			if ref.Name != "" {
+				query = ref.Name
+			}
 		}
 
-		if len(ur) > 0 {
-			uri, err := url.Parse(ur)
-			if err != nil {
-				if logDebug {
-					cmd.PrintErrf("Invalid URL: %s\n", ur)
-				}
-				os.Exit(CODE_INVALID_URL)
+		if vaultName != "" {
+			if err := akv.ValidateVaultName(vaultName); err != nil {
+				exitWithError(cmd, err)
 			}
+		}
 
-			vaultName = uri.Host
-			path := uri.Path
-			path = strings.TrimPrefix(path, "/")
-			path = strings.TrimSuffix(path, "/")
-			path = strings.TrimPrefix(path, "secrets/")
-			if len(path) > 0 {
-				query = path
+		where, _ := cmd.Flags().GetString("where")
+		var filter *akv.Filter
+		if where != "" {
+			f, err := akv.ParseFilter(where)
+			if err != nil {
+				exitWithError(cmd, err)
 			}
+			filter = f
 		}
 
-		if len(vaultName) == 0 {
-			if !logDebug {
-				cmd.PrintErrf("vault name is required. Use --vault <name> option or the URL argument.\n")
+		pattern, _ := cmd.Flags().GetString("regex")
+		var re *regexp.Regexp
+		if pattern != "" {
+			r, err := regexp.Compile(pattern)
+			if err != nil {
+				cmd.PrintErrf("Error: invalid --regex: %v\n", err)
+				os.Exit(CODE_ERROR)
 			}
-			os.Exit(5)
+			re = r
 		}
 
-		if !strings.HasSuffix(vaultName, ".vault.azure.net") {
-			vaultName += ".vault.azure.net"
+		sortBy, _ := cmd.Flags().GetString("sort")
+		compare, ok := propertiesOrder[strings.ToLower(sortBy)]
+		if sortBy != "" && !ok {
+			cmd.PrintErrf("Error: unknown --sort %q, use name, created, updated or expires\n", sortBy)
+			os.Exit(CODE_ERROR)
 		}
 
-		inter := ""
-		if deviceCode {
-			inter = "device-code"
-		}
+		client := newClient(cmd, vaultName)
 
-		if interactive {
-			inter = "interactive"
+		list, err := client.List(cmd.Context(), query)
+		if err != nil {
+			exitWithError(cmd, err)
 		}
 
-		var interactivePtr *string
-		if inter != "" {
-			interactivePtr = &inter
+		now := time.Now()
+		list = slices.DeleteFunc(list, func(props akv.SecretProperties) bool {
+			if re != nil && !re.MatchString(props.Name) {
+				return true
+			}
+			return filter != nil && !filter.Match(&props, now)
+		})
+
+		if compare != nil {
+			slices.SortStableFunc(list, compare)
 		}
 
-		creds, err := getCredential(interactivePtr, cmd.Context())
-		if err != nil {
-			if logDebug {
-				cmd.PrintErrf("Failed to get credentials: %v\n", err)
-			}
-			os.Exit(CODE_INVALID_CREDENTIALS)
+		if reverse, _ := cmd.Flags().GetBool("reverse"); reverse {
+			slices.Reverse(list)
 		}
 
-		client, err := azsecrets.NewClient("https://"+vaultName, creds, nil)
-		if err != nil {
-			if !logDebug {
-				cmd.PrintErrf("Failed to create client: %v\n", err)
-			}
-			os.Exit(CODE_CLIENT_CREATION_FAILED)
+		if limit, _ := cmd.Flags().GetInt("limit"); limit > 0 && len(list) > limit {
+			list = list[:limit]
 		}
 
-		resp := client.NewListSecretPropertiesPager(nil)
+		long, _ := cmd.Flags().GetBool("long")
+		def := ""
+		if long {
+			def = "table"
+		}
 
-		for resp.More() {
-			page, err := resp.NextPage(cmd.Context())
-			if err != nil {
-				if logDebug {
-					cmd.PrintErrf("Failed to get secret: %v\n", err)
-				}
-				os.Exit(CODE_SECRET_LIST_FAILED)
+		if outputFormat(cmd, def) == "" {
+			for _, secret := range list {
+				fmt.Fprintln(cmd.OutOrStdout(), secret.Name)
 			}
-			for _, secret := range page.Value {
-				name := secret.ID.Name()
-				match := len(query) == 0
 
-				if !match {
-					res, _ := filepath.Match(query, name)
-					match = res
-				}
-
-				if !match {
-					continue
-				}
+			os.Exit(CODE_OK)
+		}
 
-				cmd.Println(name)
-			}
+		items := make([]any, len(list))
+		for i, props := range list {
+			items[i] = newPropertiesOutput(props)
 		}
 
+		printOutput(cmd, def, outputData{
+			Items: items,
+			Columns: []outputColumn{
+				{"NAME", func(i int) string { return list[i].Name }},
+				{"ENABLED", func(i int) string { return fmt.Sprint(list[i].Enabled) }},
+				{"EXPIRES", func(i int) string { return formatTime(list[i].Expires) }},
+				{"UPDATED", func(i int) string { return formatTime(list[i].Updated) }},
+				{"CONTENT TYPE", func(i int) string { return list[i].ContentType }},
+				{"TAGS", func(i int) string { return formatTags(list[i].Tags) }},
+			},
+			Env: func(i int) (string, string) {
+				return akv.VariableName(list[i].Name), secretRefString(client, list[i].Name)
+			},
+		})
+
 		os.Exit(CODE_OK)
 	},
 }
@@ -136,6 +159,12 @@ func init() {
 	listCmd.Flags().BoolP("interactive", "i", false, "Use interactive authentication")
 	listCmd.Flags().Bool("device-code", false, "Use device code authentication")
 	listCmd.Flags().BoolP("quiet", "q", false, "Suppress output messages")
+	listCmd.Flags().BoolP("long", "l", false, "Print the properties of the secrets as a table")
+	listCmd.Flags().StringP("where", "w", "", "A filter expression on the properties of the secrets")
+	listCmd.Flags().String("regex", "", "A regular expression to filter the secrets by name")
+	listCmd.Flags().String("sort", "", "Sort by name, created, updated or expires")
+	listCmd.Flags().BoolP("reverse", "r", false, "Reverse the order of the secrets")
+	listCmd.Flags().IntP("limit", "n", 0, "The maximum number of secrets to print")
 
 	rootCmd.AddCommand(listCmd)
 
@@ -149,3 +178,70 @@ func init() {
 	// is called directly, e.g.:
 	// listCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
+
+// SecretProperties is the output of the properties of a secret.
+type SecretProperties struct {
+	Key         string            `json:"key"`
+	Enabled     bool              `json:"enabled"`
+	Managed     bool              `json:"managed,omitempty"`
+	CreatedAt   string            `json:"created_at,omitempty"`
+	UpdatedAt   string            `json:"updated_at,omitempty"`
+	ExpiresAt   string            `json:"expires_at,omitempty"`
+	StartsAt    string            `json:"starts_at,omitempty"`
+	ContentType string            `json:"content_type,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+func newPropertiesOutput(props akv.SecretProperties) SecretProperties {
+	return SecretProperties{
+		Key:         props.Name,
+		Enabled:     props.Enabled,
+		Managed:     props.Managed,
+		CreatedAt:   formatTime(props.Created),
+		UpdatedAt:   formatTime(props.Updated),
+		ExpiresAt:   formatTime(props.Expires),
+		StartsAt:    formatTime(props.NotBefore),
+		ContentType: props.ContentType,
+		Tags:        props.Tags,
+	}
+}
+
+// propertiesOrder are the orders of ls --sort.
+var propertiesOrder = map[string]func(a, b akv.SecretProperties) int{
+	"name": func(a, b akv.SecretProperties) int {
+		return strings.Compare(a.Name, b.Name)
+	},
+	"created": func(a, b akv.SecretProperties) int {
+		return compareTime(a.Created, b.Created)
+	},
+	"updated": func(a, b akv.SecretProperties) int {
+		return compareTime(a.Updated, b.Updated)
+	},
+	"expires": func(a, b akv.SecretProperties) int {
+		return compareTime(a.Expires, b.Expires)
+	},
+}
+
+// compareTime orders times oldest first, with missing times last.
+func compareTime(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+
+	return a.Compare(*b)
+}
+
+// secretRefString returns a reference to a secret of the client's vault,
+// using the akv form for vault names and the https form for vault urls.
+func secretRefString(client *akv.Client, name string) string {
+	if strings.Contains(client.Vault(), "://") {
+		return client.URL() + "/secrets/" + name
+	}
+
+	return akv.SecretRef{Vault: client.Vault(), Name: name}.String()
+}
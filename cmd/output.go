@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/hyprxlabs/go/dotenv"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// outputColumn is a column of the table and tsv formats.
+type outputColumn struct {
+	Header string
+	Value  func(i int) string
+}
+
+// outputData describes what a command prints in the formats selected with
+// the global --output and --template flags.
+type outputData struct {
+	// Items are encoded for the json, jsonl, yaml and template formats.
+	Items []any
+	// Single prints the first item instead of a list for json and yaml.
+	Single bool
+	// Columns are printed by the table and tsv formats.
+	Columns []outputColumn
+	// Env returns the variable name and value of an item for the env
+	// format, which is not supported when Env is nil.
+	Env func(i int) (string, string)
+}
+
+// outputFormat returns the format selected with --output, or def when it
+// is not set. A --template always selects the template format.
+func outputFormat(cmd *cobra.Command, def string) string {
+	if tmpl, _ := cmd.Flags().GetString("template"); tmpl != "" {
+		return "template"
+	}
+
+	format, _ := cmd.Flags().GetString("output")
+	if format == "" {
+		return def
+	}
+
+	return strings.ToLower(format)
+}
+
+// printOutput prints data in the format selected for the command, or in
+// def when none is selected. It exits the process when the format is not
+// supported or printing fails.
+func printOutput(cmd *cobra.Command, def string, data outputData) {
+	format := outputFormat(cmd, def)
+	if err := writeOutput(cmd, format, data); err != nil {
+		cmd.PrintErrf("Error: %v\n", err)
+		os.Exit(CODE_ERROR)
+	}
+}
+
+func writeOutput(cmd *cobra.Command, format string, data outputData) error {
+	out := cmd.OutOrStdout()
+
+	switch format {
+	case "json":
+		var v any = data.Items
+		if data.Single && len(data.Items) > 0 {
+			v = data.Items[0]
+		}
+
+		bits, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(out, string(bits))
+		return err
+	case "jsonl":
+		enc := json.NewEncoder(out)
+		for _, item := range data.Items {
+			if err := enc.Encode(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "yaml", "yml":
+		var v any = data.Items
+		if data.Single && len(data.Items) > 0 {
+			v = data.Items[0]
+		}
+
+		bits, err := toYAML(v)
+		if err != nil {
+			return err
+		}
+
+		_, err = out.Write(bits)
+		return err
+	case "table", "tsv":
+		if len(data.Columns) == 0 {
+			return fmt.Errorf("output %s is not supported by this command", format)
+		}
+
+		// tsv is written as is so that empty cells keep their tab.
+		var w io.Writer = out
+		var tw *tabwriter.Writer
+		if format == "table" {
+			tw = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			w = tw
+		}
+
+		headers := make([]string, len(data.Columns))
+		for i, c := range data.Columns {
+			headers[i] = c.Header
+		}
+		fmt.Fprintln(w, strings.Join(headers, "\t"))
+
+		for i := range data.Items {
+			values := make([]string, len(data.Columns))
+			for j, c := range data.Columns {
+				value := c.Value(i)
+				if format == "table" {
+					value = orDash(value)
+				} else {
+					value = strings.NewReplacer("\t", " ", "\n", " ").Replace(value)
+				}
+				values[j] = value
+			}
+			fmt.Fprintln(w, strings.Join(values, "\t"))
+		}
+
+		if tw != nil {
+			return tw.Flush()
+		}
+		return nil
+	case "env":
+		if data.Env == nil {
+			return fmt.Errorf("output env is not supported by this command")
+		}
+
+		doc := dotenv.NewDocument()
+		for i := range data.Items {
+			doc.Set(data.Env(i))
+		}
+
+		_, err := fmt.Fprint(out, doc.String())
+		return err
+	case "template":
+		text, _ := cmd.Flags().GetString("template")
+		tmpl, err := template.New("output").Funcs(template.FuncMap{
+			"json": func(v any) (string, error) {
+				bits, err := json.Marshal(v)
+				return string(bits), err
+			},
+		}).Parse(text)
+		if err != nil {
+			return fmt.Errorf("invalid template: %w", err)
+		}
+
+		// items are passed as decoded JSON so that templates use the same
+		// field names as the json output.
+		for _, item := range data.Items {
+			bits, err := json.Marshal(item)
+			if err != nil {
+				return err
+			}
+
+			var v any
+			if err := json.Unmarshal(bits, &v); err != nil {
+				return err
+			}
+
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, v); err != nil {
+				return err
+			}
+
+			if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+				buf.WriteByte('\n')
+			}
+
+			if _, err := out.Write(buf.Bytes()); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown output %q, use json, jsonl, yaml, table, env or tsv", format)
+	}
+}
+
+// toYAML encodes v as YAML using its JSON field names and order.
+func toYAML(v any) ([]byte, error) {
+	bits, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	node := &yaml.Node{}
+	if err := yaml.Unmarshal(bits, node); err != nil {
+		return nil, err
+	}
+
+	resetStyle(node)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// resetStyle drops the flow and quoting styles that decoding JSON leaves
+// on the nodes so that the encoder picks block style YAML.
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
@@ -4,14 +4,9 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"errors"
-	"fmt"
-	"net/url"
 	"os"
-	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+	"github.com/hyprxlabs/secrets-akv/akv"
 	"github.com/spf13/cobra"
 )
 
@@ -26,120 +21,23 @@ Cobra is a CLI library for Go that empowers applications.
 This application is a tool to generate the needed files
 to quickly create a Cobra application.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		vaultName, _ := cmd.Flags().GetString("vault")
-		key, _ := cmd.Flags().GetString("key")
-		interactive, _ := cmd.Flags().GetBool("interactive")
-		deviceCode, _ := cmd.Flags().GetBool("device-code")
-		logDebug, _ = cmd.Flags().GetBool("debug")
+		vaultName, key, _ := secretArgs(cmd, args)
 		force, _ := cmd.Flags().GetBool("force")
 
-		ur := ""
-		if len(args) > 0 {
-			ur = args[0]
-		}
-
-		if len(ur) > 0 {
-			uri, err := url.Parse(ur)
-			if err != nil {
-				if logDebug {
-					cmd.PrintErrf("Invalid URL: %s\n", ur)
-				}
-				os.Exit(CODE_INVALID_URL)
-			}
-
-			vaultName = uri.Host
-			path := uri.Path
-			path = strings.TrimPrefix(path, "/")
-			path = strings.TrimSuffix(path, "/")
-			path = strings.TrimPrefix(path, "secrets/")
-			if len(path) > 0 {
-				if strings.ContainsRune(path, '/') {
-					parts := strings.Split(path, "/")
-					key = parts[0]
-				} else {
-					key = path
-				}
-			}
-		}
-
-		if vaultName == "" {
-			if !logDebug {
-				cmd.PrintErrf("Vault name is required.\n")
-			}
-			os.Exit(CODE_MISSING_VAULT_NAME)
-		}
-
-		if key == "" {
-			if !logDebug {
-				cmd.PrintErrf("Key name is required.\n")
-			}
-			os.Exit(CODE_MISSING_VAULT_SECRET_NAME)
-		}
-
-		if !strings.HasSuffix(vaultName, ".vault.azure.net") {
-			vaultName += ".vault.azure.net"
-		}
-
-		inter := ""
-		if deviceCode {
-			inter = "device-code"
-		}
-
-		if interactive {
-			inter = "interactive"
-		}
-
-		var interactivePtr *string
-		if inter != "" {
-			interactivePtr = &inter
-		}
-
-		creds, err := getCredential(interactivePtr, cmd.Context())
-		if err != nil {
-			if logDebug {
-				cmd.PrintErrf("Failed to get credentials: %v\n", err)
-			}
-			os.Exit(CODE_INVALID_CREDENTIALS)
-		}
-
-		client, err := azsecrets.NewClient("https://"+vaultName, creds, nil)
-		if err != nil {
-			if !logDebug {
-				cmd.PrintErrf("Failed to create client: %v\n", err)
-			}
-			os.Exit(CODE_CLIENT_CREATION_FAILED)
-		}
+		client := newClient(cmd, vaultName)
 
-		if !force {
-			fmt.Println("Purge secret [y/n]:")
-			confirm := ""
-			for confirm != "y" && confirm != "n" {
-				fmt.Scanln(&confirm)
-				if confirm == "n" {
-					if !logDebug {
-						cmd.Println("Operation cancelled.")
-					}
-					os.Exit(CODE_OPERATION_CANCELLED)
-				} else if confirm != "y" {
-					cmd.PrintErrf("Invalid input. Please enter 'y' or 'n'.\n")
-				}
-			}
+		if !force && !confirm(cmd, "Purge secret [y/n]:") {
+			os.Exit(CODE_OPERATION_CANCELLED)
 		}
 
-		_, err = client.PurgeDeletedSecret(cmd.Context(), key, nil)
-		var respErr *azcore.ResponseError
-		if errors.As(err, &respErr) && respErr.ErrorCode == "SecretNotFound" {
-			if logDebug {
-				cmd.Printf("Secret %s already purged %s.\n", key, vaultName)
-			}
+		err := client.Purge(cmd.Context(), key)
+		if akv.IsNotFound(err) {
+			cmd.Printf("Secret %s already purged %s.\n", key, vaultName)
 			os.Exit(CODE_OK)
 		}
 
 		if err != nil {
-			if logDebug {
-				cmd.PrintErrf("Failed to purge secret: %v\n", err)
-			}
-			os.Exit(CODE_SECRET_REMOVE_FAILED)
+			exitWithError(cmd, err)
 		}
 
 		os.Exit(CODE_OK)
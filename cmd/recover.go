@@ -0,0 +1,52 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"os"
+
+	"github.com/hyprxlabs/secrets-akv/akv"
+	"github.com/spf13/cobra"
+)
+
+// recoverCmd represents the recover command
+var recoverCmd = &cobra.Command{
+	Use:   "recover [url]",
+	Short: "Recovers a soft deleted secret",
+	Long: `Recovers a soft deleted secret with all of its versions and waits, up to
+--timeout, until it exists again. Use 'deleted ls' to list the deleted secrets.`,
+	Example: `hx-secrets-akv recover akv://myvault/db-pass
+hx-secrets-akv recover --vault myvault --key db-pass --no-wait`,
+	Run: func(cmd *cobra.Command, args []string) {
+		vaultName, key, _ := secretArgs(cmd, args)
+		noWait, _ := cmd.Flags().GetBool("no-wait")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		client := newClient(cmd, vaultName)
+
+		secret, err := client.Recover(cmd.Context(), key, &akv.RecoverOptions{NoWait: noWait, Timeout: timeout})
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+
+		if secret != nil {
+			cmd.Println("Secret recovered. version: " + secret.Version)
+		} else {
+			cmd.Println("Secret recovery started.")
+		}
+
+		os.Exit(CODE_OK)
+	},
+}
+
+func init() {
+	recoverCmd.Flags().StringP("vault", "v", "", "Key Vault name (e.g., myvault)")
+	recoverCmd.Flags().StringP("key", "k", "", "Key name in the Key Vault")
+	recoverCmd.Flags().Bool("no-wait", false, "Return without waiting until the secret can be read")
+	recoverCmd.Flags().Duration("timeout", akv.DefaultRecoverTimeout, "The time to wait for the recovery to complete")
+	recoverCmd.Flags().BoolP("interactive", "i", false, "Use interactive authentication")
+	recoverCmd.Flags().Bool("device-code", false, "Use device code authentication")
+
+	rootCmd.AddCommand(recoverCmd)
+}
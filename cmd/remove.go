@@ -4,15 +4,10 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"context"
-	"errors"
 	"fmt"
-	"net/url"
 	"os"
-	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+	"github.com/hyprxlabs/secrets-akv/akv"
 	"github.com/spf13/cobra"
 )
 
@@ -27,150 +22,47 @@ var removeCmd = &cobra.Command{
 	If --force is specified, the command will not prompt for confirmation before deleting the secret.`,
 
 	Run: func(cmd *cobra.Command, args []string) {
-		vaultName, _ := cmd.Flags().GetString("vault")
-		key, _ := cmd.Flags().GetString("key")
-		interactive, _ := cmd.Flags().GetBool("interactive")
-		deviceCode, _ := cmd.Flags().GetBool("device-code")
+		vaultName, key, _ := secretArgs(cmd, args)
 		purge, _ := cmd.Flags().GetBool("purge")
-		logDebug, _ = cmd.Flags().GetBool("debug")
 		force, _ := cmd.Flags().GetBool("force")
 
-		ur := ""
-		if len(args) > 0 {
-			ur = args[0]
-		}
-
-		if len(ur) > 0 {
-			uri, err := url.Parse(ur)
-			if err != nil {
-				if logDebug {
-					cmd.PrintErrf("Invalid URL: %s\n", ur)
-				}
-				os.Exit(CODE_INVALID_URL)
-			}
-
-			vaultName = uri.Host
-			path := uri.Path
-			path = strings.TrimPrefix(path, "/")
-			path = strings.TrimSuffix(path, "/")
-			path = strings.TrimPrefix(path, "secrets/")
-			if len(path) > 0 {
-				if strings.ContainsRune(path, '/') {
-					parts := strings.SplitN(path, "/", 2)
-					key = parts[0]
-				} else {
-					key = path
-				}
-			}
-		}
-
-		if vaultName == "" {
-			if logDebug {
-				cmd.PrintErrf("Vault name is required\n")
-			}
-			os.Exit(CODE_MISSING_VAULT_NAME)
-		}
-
-		if key == "" {
-			if logDebug {
-				cmd.PrintErrf("Key name is required\n")
-			}
-			os.Exit(CODE_MISSING_VAULT_SECRET_NAME)
-		}
+		client := newClient(cmd, vaultName)
 
-		if !strings.HasSuffix(vaultName, ".vault.azure.net") {
-			vaultName += ".vault.azure.net"
+		if !force && !confirm(cmd, "Delete secret [y/n]:") {
+			os.Exit(CODE_OPERATION_CANCELLED)
 		}
 
-		inter := ""
-		if deviceCode {
-			inter = "device-code"
-		}
-
-		if interactive {
-			inter = "interactive"
-		}
-
-		var interactivePtr *string
-		if inter != "" {
-			interactivePtr = &inter
-		}
-
-		creds, err := getCredential(interactivePtr, cmd.Context())
-		if err != nil {
-			if logDebug {
-				cmd.PrintErrf("Failed to get credentials: %v\n", err)
-			}
-			os.Exit(CODE_INVALID_CREDENTIALS)
-		}
-
-		client, err := azsecrets.NewClient("https://"+vaultName, creds, nil)
-		if err != nil {
-			if logDebug {
-				cmd.PrintErrf("Failed to create client: %v\n", err)
-			}
-			os.Exit(CODE_CLIENT_CREATION_FAILED)
-		}
-
-		if !force {
-			fmt.Println("Delete secret [y/n]:")
-			confirm := ""
-			for confirm != "y" && confirm != "n" {
-				fmt.Scanln(&confirm)
-				if confirm == "n" {
-					if logDebug {
-						cmd.Println("Operation cancelled.")
-					}
-					os.Exit(CODE_OPERATION_CANCELLED)
-				} else if confirm != "y" {
-					cmd.PrintErrf("Invalid input. Please enter 'y' or 'n'.\n")
-				}
-			}
-		}
-
-		resp, err := client.DeleteSecret(cmd.Context(), key, nil)
-		var respErr *azcore.ResponseError
-		if errors.As(err, &respErr) && respErr.ErrorCode == "SecretNotFound" {
-			if logDebug {
-				cmd.Printf("Secret %s already removed from %s.\n", key, vaultName)
-			}
+		err := client.Delete(cmd.Context(), key, &akv.DeleteOptions{Purge: purge})
+		if akv.IsNotFound(err) {
+			cmd.Printf("Secret %s already removed from %s.\n", key, vaultName)
 			os.Exit(CODE_OK)
 		}
 
 		if err != nil {
-			if logDebug {
-				cmd.PrintErrf("Failed to delete secret: %v\n", err)
-			}
-			os.Exit(CODE_SECRET_REMOVE_FAILED)
-		}
-
-		if resp.ScheduledPurgeDate != nil && purge {
-			pager := client.NewListDeletedSecretPropertiesPager(nil)
-			for pager.More() {
-				page, err := pager.NextPage(context.TODO())
-				if err != nil {
-					// TODO: handle error
-				}
-				for _, secret := range page.Value {
-					if secret.ID.Name() != key {
-						continue
-					}
-
-					_, err := client.PurgeDeletedSecret(context.TODO(), secret.ID.Name(), nil)
-					if err != nil {
-						if !logDebug {
-							cmd.PrintErrf("Failed to purge secret: %v\n", err)
-						}
-						os.Exit(CODE_SECRET_REMOVE_FAILED)
-					}
-				}
-			}
+			exitWithError(cmd, err)
 		}
 
 		os.Exit(CODE_OK)
 	},
 }
 
+// confirm prompts the user with message until they answer y or n.
+func confirm(cmd *cobra.Command, message string) bool {
+	fmt.Println(message)
+	answer := ""
+	for answer != "y" && answer != "n" {
+		fmt.Scanln(&answer)
+		if answer == "n" {
+			cmd.Println("Operation cancelled.")
+			return false
+		} else if answer != "y" {
+			cmd.PrintErrf("Invalid input. Please enter 'y' or 'n'.\n")
+		}
+	}
+
+	return true
+}
+
 func init() {
 	rootCmd.AddCommand(removeCmd)
 
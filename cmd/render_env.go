@@ -0,0 +1,117 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/hyprxlabs/go/dotenv"
+	"github.com/hyprxlabs/secrets-akv/akv"
+	"github.com/spf13/cobra"
+)
+
+// renderEnvCmd represents the render-env command
+var renderEnvCmd = &cobra.Command{
+	Use:   "render-env <file>",
+	Short: "Replaces secret references in a dotenv file with their values",
+	Long: `Reads a dotenv file and replaces every value that is a secret reference
+(akv://, https:// or @Microsoft.KeyVault(...)) with the value of the secret.
+Comments, ordering and other values are kept.
+
+The result is written to stdout, or to --output with 0600 permissions. Use -
+as the file to read from stdin.`,
+	Example: `hx-secrets-akv render-env .env.tpl
+hx-secrets-akv render-env .env.tpl --output .env`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+
+		var bits []byte
+		var err error
+		if args[0] == "-" {
+			bits, err = io.ReadAll(cmd.InOrStdin())
+		} else {
+			bits, err = os.ReadFile(args[0])
+		}
+		if err != nil {
+			cmd.PrintErrf("Failed to read %s: %v\n", args[0], err)
+			os.Exit(CODE_ERROR)
+		}
+
+		doc, err := dotenv.Parse(string(bits))
+		if err != nil {
+			cmd.PrintErrf("Failed to parse %s: %v\n", args[0], err)
+			os.Exit(CODE_ERROR)
+		}
+
+		endpoint := flagOrEnv(cmd, "endpoint", "HX_AKV_ENDPOINT")
+		refs := map[string]string{}
+		for _, node := range doc.ToArray() {
+			if node.Type == dotenv.VARIABLE_TOKEN && node.Key != nil && akv.IsEndpointRef(node.Value, endpoint) {
+				refs[*node.Key] = node.Value
+			}
+		}
+
+		if len(refs) > 0 {
+			resolver := newResolver(cmd)
+			resolver.SetConcurrency(concurrency)
+
+			values, err := resolver.ResolveAll(cmd.Context(), refs)
+			if err != nil {
+				exitWithError(cmd, err)
+			}
+
+			for k, v := range values {
+				doc.Set(k, v)
+			}
+		}
+
+		content := doc.String()
+		if output == "" || output == "-" {
+			cmd.OutOrStdout().Write([]byte(content))
+			return
+		}
+
+		if err := writeSecretFile(output, []byte(content)); err != nil {
+			cmd.PrintErrf("Failed to write %s: %v\n", output, err)
+			os.Exit(CODE_ERROR)
+		}
+	},
+}
+
+// writeSecretFile writes data to file so that only the owner can read it,
+// even when the file already existed with wider permissions. The data goes
+// to a temporary 0600 file in the same directory that replaces file, so it
+// is never readable by others.
+func writeSecretFile(file string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), file)
+}
+
+func init() {
+	renderEnvCmd.Flags().StringP("output", "o", "", "File to write the result to (default stdout)")
+	renderEnvCmd.Flags().Int("concurrency", akv.DefaultConcurrency, "Number of secrets to read at the same time")
+	renderEnvCmd.Flags().BoolP("interactive", "i", false, "Use interactive authentication")
+	renderEnvCmd.Flags().Bool("device-code", false, "Use device code authentication")
+
+	rootCmd.AddCommand(renderEnvCmd)
+}
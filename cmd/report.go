@@ -0,0 +1,223 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// reportCmd represents the report command
+var reportCmd = &cobra.Command{
+	Use:   "report [vault...]",
+	Short: "Exports the expiry dates of secrets as a calendar or metrics",
+	Long: `Scans one or more vaults like 'expiring' and exports the expiry dates of
+their secrets in one of these formats:
+
+  ics         an iCalendar feed with an all day event on the day each
+              secret expires, for calendar subscriptions
+  prometheus  Prometheus text format metrics for the textfile collector of
+              node_exporter
+
+The prometheus format exposes akv_secret_expiry_timestamp_seconds and
+akv_secret_not_before_timestamp_seconds gauges with vault and name labels,
+and akv_secret_expiry_status with the status of every secret, as reported by
+'expiring' with --within. Secrets without an expiry date have no expiry
+gauge and no calendar event.
+
+With --output the report is written to a temporary file that is then renamed,
+so that readers never see a partial file.`,
+	Example: `hx-secrets-akv report --vault myvault --format ics -o expiry.ics
+hx-secrets-akv report a b --format prometheus -o /var/lib/node_exporter/akv.prom`,
+	Run: func(cmd *cobra.Command, args []string) {
+		vaults, _ := cmd.Flags().GetStringArray("vault")
+		vaults = append(vaults, args...)
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+
+		var render func([]ExpiringSecret, time.Time) string
+		switch strings.ToLower(format) {
+		case "ics", "ical":
+			render = formatICS
+		case "prometheus", "prom":
+			render = formatPrometheus
+		default:
+			cmd.PrintErrf("Error: unknown format %q, use ics or prometheus\n", format)
+			os.Exit(CODE_ERROR)
+		}
+
+		report := scanExpiry(cmd, vaults)
+		content := render(report, time.Now())
+
+		if output == "" || output == "-" {
+			fmt.Fprint(cmd.OutOrStdout(), content)
+			os.Exit(CODE_OK)
+		}
+
+		if err := writeFileAtomic(output, []byte(content)); err != nil {
+			cmd.PrintErrf("Failed to write %s: %v\n", output, err)
+			os.Exit(CODE_ERROR)
+		}
+
+		os.Exit(CODE_OK)
+	},
+}
+
+func init() {
+	reportCmd.Flags().StringArrayP("vault", "v", nil, "The name of an Azure Key Vault to scan, can be repeated")
+	reportCmd.Flags().StringP("format", "f", "prometheus", "The report format: ics or prometheus")
+	reportCmd.Flags().StringP("output", "o", "", "File to write the report to (default stdout)")
+	reportCmd.Flags().StringP("query", "s", "", "A query to filter the secrets by name")
+	reportCmd.Flags().StringP("where", "w", "", "A filter expression on the properties of the secrets")
+	reportCmd.Flags().String("within", "30d", "Secrets that expire within this duration have the expiring status")
+	reportCmd.Flags().Bool("include-disabled", false, "Include disabled secrets")
+	reportCmd.Flags().BoolP("interactive", "i", false, "Use interactive authentication")
+	reportCmd.Flags().Bool("device-code", false, "Use device code authentication")
+
+	rootCmd.AddCommand(reportCmd)
+}
+
+// formatPrometheus formats the report as Prometheus text format metrics.
+func formatPrometheus(report []ExpiringSecret, now time.Time) string {
+	var sb strings.Builder
+
+	gauge := func(name, help string, value func(s ExpiringSecret) *time.Time) {
+		fmt.Fprintf(&sb, "# HELP %s %s\n", name, help)
+		fmt.Fprintf(&sb, "# TYPE %s gauge\n", name)
+		for _, s := range report {
+			if t := value(s); t != nil {
+				fmt.Fprintf(&sb, "%s{vault=\"%s\",name=\"%s\"} %d\n", name, promLabel(s.Vault), promLabel(s.Key), t.Unix())
+			}
+		}
+	}
+
+	gauge("akv_secret_expiry_timestamp_seconds", "Time the secret expires, in seconds since the epoch.",
+		func(s ExpiringSecret) *time.Time { return s.props.Expires })
+	gauge("akv_secret_not_before_timestamp_seconds", "Time the secret becomes active, in seconds since the epoch.",
+		func(s ExpiringSecret) *time.Time { return s.props.NotBefore })
+
+	sb.WriteString("# HELP akv_secret_expiry_status Expiry status of the secret, always 1.\n")
+	sb.WriteString("# TYPE akv_secret_expiry_status gauge\n")
+	for _, s := range report {
+		fmt.Fprintf(&sb, "akv_secret_expiry_status{vault=\"%s\",name=\"%s\",status=\"%s\"} 1\n", promLabel(s.Vault), promLabel(s.Key), s.Status)
+	}
+
+	sb.WriteString("# HELP akv_secret_report_timestamp_seconds Time the report was created, in seconds since the epoch.\n")
+	sb.WriteString("# TYPE akv_secret_report_timestamp_seconds gauge\n")
+	fmt.Fprintf(&sb, "akv_secret_report_timestamp_seconds %d\n", now.Unix())
+
+	return sb.String()
+}
+
+// promLabel escapes a Prometheus label value.
+func promLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// formatICS formats the report as an iCalendar feed with an all day event
+// on the expiry date of every secret that has one.
+func formatICS(report []ExpiringSecret, now time.Time) string {
+	var sb strings.Builder
+
+	line := func(s string) {
+		sb.WriteString(foldICS(s))
+		sb.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//hyprxlabs//hx-secrets-akv//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:Key Vault secret expiry")
+
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, s := range report {
+		if s.props.Expires == nil {
+			continue
+		}
+
+		day := s.props.Expires.UTC()
+		description := fmt.Sprintf("Secret %s in vault %s expires at %s (status %s).",
+			s.Key, s.Vault, day.Format(time.RFC3339), s.Status)
+
+		line("BEGIN:VEVENT")
+		line("UID:" + icsText(s.Vault+"/"+s.Key) + "@hx-secrets-akv")
+		line("DTSTAMP:" + stamp)
+		line("DTSTART;VALUE=DATE:" + day.Format("20060102"))
+		line("DTEND;VALUE=DATE:" + day.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY:" + icsText(fmt.Sprintf("Secret %s/%s expires", s.Vault, s.Key)))
+		line("DESCRIPTION:" + icsText(description))
+		line("CATEGORIES:" + icsText(string(s.Status)))
+		line("TRANSP:TRANSPARENT")
+		line("END:VEVENT")
+	}
+
+	line("END:VCALENDAR")
+
+	return sb.String()
+}
+
+// icsText escapes an iCalendar text value.
+func icsText(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(value)
+}
+
+// foldICS folds a content line into lines of at most 75 octets, as
+// required by RFC 5545, without splitting utf-8 sequences.
+func foldICS(s string) string {
+	if len(s) <= 75 {
+		return s
+	}
+
+	var sb strings.Builder
+	limit := 75
+	n := 0
+	for _, r := range s {
+		size := len(string(r))
+		if n+size > limit {
+			sb.WriteString("\r\n ")
+			// continuation lines start with a space.
+			limit = 74
+			n = 0
+		}
+		sb.WriteRune(r)
+		n += size
+	}
+
+	return sb.String()
+}
+
+// writeFileAtomic writes data to a temporary file next to file and renames
+// it to file.
+func writeFileAtomic(file string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), file)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hyprxlabs/secrets-akv/akv"
+	"github.com/spf13/cobra"
+)
+
+// restoreCmd represents the restore command
+var restoreCmd = &cobra.Command{
+	Use:   "restore <backup>",
+	Short: "Restores secrets from a backup directory or archive",
+	Long: `Restores the secrets of a backup created by the backup command, with all of
+their versions, into a vault. The target vault defaults to the vault the
+backup was taken from and must be in the same subscription and geography.
+
+Restoring fails for secrets that already exist, including deleted secrets
+that have not been purged. Use --skip-existing to leave those alone and
+restore the rest.`,
+	Example: `hx-secrets-akv restore ./myvault-backup-20250101T000000Z
+hx-secrets-akv restore app-secrets.tar.gz --vault myvault-dr --query 'app-db-*'
+hx-secrets-akv restore myvault.tar --skip-existing`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		vaultName, _ := cmd.Flags().GetString("vault")
+		query, _ := cmd.Flags().GetString("query")
+		skipExisting, _ := cmd.Flags().GetBool("skip-existing")
+
+		if query != "" {
+			if _, err := filepath.Match(query, ""); err != nil {
+				exitWithError(cmd, akv.ErrInvalidPattern)
+			}
+		}
+
+		manifest, blobs, err := readBackup(args[0])
+		if err != nil {
+			cmd.PrintErrf("Failed to read backup %s: %v\n", args[0], err)
+			os.Exit(CODE_ERROR)
+		}
+
+		if vaultName == "" && flagOrEnv(cmd, "endpoint", "HX_AKV_ENDPOINT") == "" {
+			vaultName = manifest.Vault
+		}
+
+		if vaultName != "" {
+			if err := akv.ValidateVaultName(vaultName); err != nil {
+				exitWithError(cmd, err)
+			}
+		}
+
+		client := newClient(cmd, vaultName)
+
+		restored, skipped := 0, 0
+		for _, entry := range manifest.Secrets {
+			if query != "" {
+				if ok, _ := filepath.Match(strings.ToLower(query), strings.ToLower(entry.Name)); !ok {
+					continue
+				}
+			}
+
+			_, err := client.Restore(cmd.Context(), blobs[entry.File])
+			if err != nil {
+				if skipExisting && akv.IsConflict(err) {
+					skipped++
+					fmt.Fprintf(cmd.OutOrStdout(), "%-9s %s\n", "skipped", entry.Name)
+					continue
+				}
+
+				exitWithError(cmd, err)
+			}
+
+			restored++
+			fmt.Fprintf(cmd.OutOrStdout(), "%-9s %s\n", "restored", entry.Name)
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "%d restored, %d skipped\n", restored, skipped)
+		os.Exit(CODE_OK)
+	},
+}
+
+func init() {
+	restoreCmd.Flags().StringP("vault", "v", "", "Vault to restore into (default the vault of the backup)")
+	restoreCmd.Flags().StringP("query", "s", "", "Only restore the secrets whose name matches the query")
+	restoreCmd.Flags().Bool("skip-existing", false, "Skip secrets that already exist instead of failing")
+	restoreCmd.Flags().BoolP("interactive", "i", false, "Use interactive authentication")
+	restoreCmd.Flags().Bool("device-code", false, "Use device code authentication")
+
+	rootCmd.AddCommand(restoreCmd)
+}
@@ -0,0 +1,56 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"os"
+
+	"github.com/hyprxlabs/secrets-akv/akv"
+	"github.com/spf13/cobra"
+)
+
+// rollbackCmd represents the rollback command
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback [url]",
+	Short: "Restores an earlier version of a secret as the current version",
+	Long: `Reads the value, content type and tags of an earlier version of a secret and
+writes them as a new current version.
+
+--to takes a version id, or -N for the version N versions before the current
+one; -1 is the previous version. Use 'versions' to list them. With
+--disable-current the version that was current before the rollback is
+disabled so that it cannot be read anymore.`,
+	Example: `hx-secrets-akv rollback akv://myvault/db-pass --to -1
+hx-secrets-akv rollback --vault myvault --key db-pass --to 5597107f635f00c5df4ebe476dd15658 --disable-current`,
+	Run: func(cmd *cobra.Command, args []string) {
+		vaultName, key, _ := secretArgs(cmd, args)
+		to, _ := cmd.Flags().GetString("to")
+		disableCurrent, _ := cmd.Flags().GetBool("disable-current")
+
+		client := newClient(cmd, vaultName)
+
+		secret, err := client.Rollback(cmd.Context(), key, to, &akv.RollbackOptions{
+			DisableCurrent: disableCurrent,
+		})
+		if secret != nil {
+			cmd.Println("Secret rolled back. version: " + secret.Version)
+		}
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+
+		os.Exit(CODE_OK)
+	},
+}
+
+func init() {
+	rollbackCmd.Flags().StringP("vault", "v", "", "Key Vault name (e.g., myvault)")
+	rollbackCmd.Flags().StringP("key", "k", "", "Key name in the Key Vault")
+	rollbackCmd.Flags().String("to", "-1", "Version id to roll back to, or -N for N versions back")
+	rollbackCmd.Flags().Bool("disable-current", false, "Disable the version that was current before the rollback")
+	rollbackCmd.Flags().BoolP("interactive", "i", false, "Use interactive authentication")
+	rollbackCmd.Flags().Bool("device-code", false, "Use device code authentication")
+
+	rootCmd.AddCommand(rollbackCmd)
+}
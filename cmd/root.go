@@ -27,6 +27,7 @@ const (
 	CODE_SECRET_LIST_FAILED        = 19
 	CODE_SECRET_CONFIG_FAILED      = 20
 	CODE_SECRET_CONFIG_NOT_FOUND   = 21
+	CODE_SECRET_EXPIRING           = 22
 	CODE_OPERATION_CANCELLED       = 99
 )
 
@@ -62,6 +63,12 @@ func init() {
 	// will be global for your application.
 
 	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.secrets-akv.yaml)")
+	rootCmd.PersistentFlags().String("cloud", "", "Azure cloud of the vault: public, china or usgov (default from HX_AKV_CLOUD)")
+	rootCmd.PersistentFlags().String("endpoint", "", "Vault url to use instead of the one derived from the vault name (default from HX_AKV_ENDPOINT)")
+	rootCmd.PersistentFlags().String("access-token", "", "Static bearer token to authenticate with, for emulators and proxies (default from HX_AKV_ACCESS_TOKEN)")
+	rootCmd.PersistentFlags().StringP("output", "o", "", "Output format: json, jsonl, yaml, table, env or tsv (commands that write files use -o for the file path)")
+	rootCmd.PersistentFlags().String("template", "", "Go template to print each item with, using the json field names")
+	rootCmd.PersistentFlags().String("ca-file", "", "PEM file with additional trusted CA certificates (default from HX_AKV_CA_FILE)")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
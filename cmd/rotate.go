@@ -0,0 +1,319 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hyprxlabs/secrets-akv/akv"
+	"github.com/mashiike/longduration"
+	"github.com/spf13/cobra"
+)
+
+// rotateCmd represents the rotate command
+var rotateCmd = &cobra.Command{
+	Use:   "rotate [url]",
+	Short: "Regenerates secrets that are due for rotation",
+	Long: `Regenerates the secrets whose rotation policy says they are due. The
+policy is read from the tags of each secret:
+
+  rotate-every   how long a value is valid, e.g. 90d (required)
+  rotate-before  how long before the expiry a value is rotated, e.g. 7d
+  length         the length of generated values, by default the size of
+                 the profile or 32
+  charset        a generator profile such as nist, sql-server, hex64 or a
+                 profile of the configuration, see 'generate', one of the
+                 character sets default, alnum, alpha, hex and digits, or
+                 chars: followed by the characters to use
+
+A secret is due when it expires within rotate-before or, without an expiry,
+when it was last updated more than rotate-every ago. The new version keeps
+the tags and content type of the current one, gets the policy written back
+to its tags and expires rotate-every from now.
+
+Given a secret, only that secret is rotated; the --every, --before, --length
+and --charset flags then override its policy. A secret that has no policy
+gets one this way and is rotated right away. Otherwise every enabled secret
+of the vault with a rotate-every tag, matching --query and --where, is
+checked. Use --force to rotate secrets that are not due and --dry-run to
+only print what is due.
+
+Dual secrets are kept in two slots, <name>-primary and <name>-secondary,
+tagged rotate-mode=dual, for services that accept two valid values at the
+same time. Consumers use the slot tagged active=true, see 'get --active'.
+Each rotation regenerates the other slot, marks it active and only then
+marks the previous slot inactive, so the value consumers hold stays valid
+until the rotation after next; slots therefore expire two periods after
+they are written. Use --dual with --every to create the slots.
+
+With --hook, or the rotate.hook configuration, each new version is written
+disabled and the hook command is run with the new value on stdin and
+HX_AKV_VAULT, HX_AKV_SECRET_NAME, HX_AKV_SECRET_VERSION and
+HX_AKV_PREVIOUS_VERSION in its environment, for example to change the
+password of a database user. The new version is only enabled when the hook
+exits with 0; otherwise it stays disabled and the previous value is written
+back as the latest version.`,
+	Example: `hx-secrets-akv rotate --vault myvault
+hx-secrets-akv rotate --vault myvault --query db-* --dry-run
+hx-secrets-akv rotate akv://myvault/db-pass --force
+hx-secrets-akv rotate akv://myvault/api-key --every 30d --before 5d --length 40 --charset url-safe
+hx-secrets-akv rotate akv://myvault/db-pass --hook ./set-db-password.sh
+hx-secrets-akv rotate akv://myvault/storage-key --dual --every 30d --hook ./set-storage-key.sh`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ref := secretRef(cmd, args)
+		vaultName := ref.Vault
+		query, _ := cmd.Flags().GetString("query")
+		force, _ := cmd.Flags().GetBool("force")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		single := ref.Name != "" && !strings.ContainsAny(ref.Name, "*?[")
+		if ref.Name != "" && !single {
+			query = ref.Name
+		}
+
+		if vaultName != "" {
+			if err := akv.ValidateVaultName(vaultName); err != nil {
+				exitWithError(cmd, err)
+			}
+		}
+
+		var filter *akv.Filter
+		if where, _ := cmd.Flags().GetString("where"); where != "" {
+			f, err := akv.ParseFilter(where)
+			if err != nil {
+				exitWithError(cmd, err)
+			}
+			filter = f
+		}
+
+		client := newClient(cmd, vaultName)
+		hook := rotationHook(cmd, client.Vault())
+		now := time.Now()
+
+		var targets []rotateTarget
+		if single {
+			target, err := singleRotateTarget(cmd, client, ref.Name)
+			if err != nil {
+				exitWithError(cmd, err)
+			}
+			targets = append(targets, target)
+		} else {
+			list, err := client.List(cmd.Context(), query)
+			if err != nil {
+				exitWithError(cmd, err)
+			}
+
+			seen := map[string]bool{}
+			for _, props := range list {
+				if !props.Enabled || props.Tag(akv.TagRotateEvery) == "" {
+					continue
+				}
+
+				if filter != nil && !filter.Match(&props, now) {
+					continue
+				}
+
+				// the slots of a dual secret are rotated together.
+				base, ok := akv.DualName(props.Name)
+				if !ok || props.Tag(akv.TagRotateMode) != "dual" {
+					targets = append(targets, rotateTarget{name: props.Name, props: props})
+					continue
+				}
+
+				if seen[base] {
+					continue
+				}
+				seen[base] = true
+
+				d, err := client.GetDual(cmd.Context(), base)
+				if err != nil {
+					exitWithError(cmd, err)
+				}
+				targets = append(targets, rotateTarget{name: base, props: d.Active().SecretProperties, dual: true})
+			}
+		}
+
+		rotated, skipped, failed := 0, 0, 0
+		for _, target := range targets {
+			policy, err := akv.ParseRotationPolicy(target.props.Tags)
+			// a secret without a policy is rotated when it gets one.
+			adopted := false
+			if err == nil && single {
+				adopted = policy == nil
+				policy, err = rotationPolicyFlags(cmd, policy)
+			}
+			if err == nil && policy == nil {
+				err = fmt.Errorf("%w: the secret has no %s tag", akv.ErrInvalidPolicy, akv.TagRotateEvery)
+			}
+			if err != nil {
+				failed++
+				fmt.Fprintf(cmd.OutOrStdout(), "%-9s %s: %v\n", "failed", target.name, err)
+				continue
+			}
+
+			if target.dual && !policy.Dual {
+				// adopting a dual policy also moves existing slots to it.
+				p := *policy
+				p.Dual = true
+				policy = &p
+			}
+
+			if !force && !adopted && !policy.Due(&target.props, now) {
+				skipped++
+				fmt.Fprintf(cmd.OutOrStdout(), "%-9s %s (expires %s)\n", "skip", target.name, orDash(formatTime(target.props.Expires)))
+				continue
+			}
+
+			if dryRun {
+				rotated++
+				fmt.Fprintf(cmd.OutOrStdout(), "%-9s %s\n", "rotate", target.name)
+				continue
+			}
+
+			options := &akv.RotateOptions{Policy: policy, Hook: hook, Profiles: lookupProfile}
+			var secret *akv.Secret
+			if target.dual {
+				secret, err = client.RotateDual(cmd.Context(), target.name, options)
+			} else {
+				secret, err = client.Rotate(cmd.Context(), target.name, options)
+			}
+			if err != nil {
+				failed++
+				fmt.Fprintf(cmd.OutOrStdout(), "%-9s %s: %v\n", "failed", target.name, err)
+				continue
+			}
+
+			rotated++
+			if target.dual {
+				fmt.Fprintf(cmd.OutOrStdout(), "%-9s %s (active %s, version %s, expires %s)\n", "rotate", target.name, secret.Name, secret.Version, formatTime(secret.Expires))
+			} else {
+				fmt.Fprintf(cmd.OutOrStdout(), "%-9s %s (version %s, expires %s)\n", "rotate", target.name, secret.Version, formatTime(secret.Expires))
+			}
+		}
+
+		summary := fmt.Sprintf("%d rotated, %d skipped, %d failed", rotated, skipped, failed)
+		if dryRun {
+			summary = "dry run: " + summary
+		}
+
+		fmt.Fprintln(cmd.OutOrStdout(), summary)
+		if failed > 0 {
+			os.Exit(CODE_SECRET_ROTATION_FAILED)
+		}
+
+		os.Exit(CODE_OK)
+	},
+}
+
+func init() {
+	rotateCmd.Flags().StringP("vault", "v", "", "The name of the Azure Key Vault")
+	rotateCmd.Flags().StringP("key", "k", "", "The name of a secret to rotate")
+	rotateCmd.Flags().StringP("query", "s", "", "A query to filter the secrets by name")
+	rotateCmd.Flags().StringP("where", "w", "", "A filter expression on the properties of the secrets")
+	rotateCmd.Flags().BoolP("force", "f", false, "Rotate secrets that are not due")
+	rotateCmd.Flags().Bool("dry-run", false, "Print the secrets that are due without rotating them")
+	rotateCmd.Flags().String("every", "", "Override the rotate-every policy of a single secret")
+	rotateCmd.Flags().String("before", "", "Override the rotate-before policy of a single secret")
+	rotateCmd.Flags().Int16("length", 0, "Override the length policy of a single secret")
+	rotateCmd.Flags().String("charset", "", "Override the charset policy of a single secret: a profile, a character set or chars:<characters>")
+	rotateCmd.Flags().Bool("dual", false, "Rotate a single secret as a dual secret with -primary and -secondary slots")
+	addHookFlags(rotateCmd)
+	rotateCmd.Flags().BoolP("interactive", "i", false, "Use interactive authentication")
+	rotateCmd.Flags().Bool("device-code", false, "Use device code authentication")
+
+	rootCmd.AddCommand(rotateCmd)
+}
+
+// rotateTarget is a secret, or a dual secret, checked by rotate. props
+// are those of the secret or of the active slot.
+type rotateTarget struct {
+	name  string
+	props akv.SecretProperties
+	dual  bool
+}
+
+// singleRotateTarget reads the secret rotate was given. A name that only
+// exists as the slots of a dual secret, or any name with --dual, is a dual
+// secret, which may not exist yet when --every is given.
+func singleRotateTarget(cmd *cobra.Command, client *akv.Client, name string) (rotateTarget, error) {
+	dual, _ := cmd.Flags().GetBool("dual")
+	if !dual {
+		secret, err := client.Get(cmd.Context(), name, "")
+		if err == nil {
+			return rotateTarget{name: name, props: secret.SecretProperties}, nil
+		}
+
+		if !akv.IsNotFound(err) {
+			return rotateTarget{}, err
+		}
+
+		if _, derr := client.GetDual(cmd.Context(), name); derr != nil {
+			return rotateTarget{}, err
+		}
+	}
+
+	target := rotateTarget{name: name, dual: true, props: akv.SecretProperties{Name: name}}
+	d, err := client.GetDual(cmd.Context(), name)
+	if err != nil {
+		if akv.IsNotFound(err) && cmd.Flags().Changed("every") {
+			return target, nil
+		}
+		return rotateTarget{}, err
+	}
+
+	target.props = d.Active().SecretProperties
+	return target, nil
+}
+
+// rotationPolicyFlags applies the --every, --before, --length and
+// --charset flags to policy, which may be nil.
+func rotationPolicyFlags(cmd *cobra.Command, policy *akv.RotationPolicy) (*akv.RotationPolicy, error) {
+	flags := cmd.Flags()
+	if !flags.Changed("every") && !flags.Changed("before") && !flags.Changed("length") && !flags.Changed("charset") {
+		return policy, nil
+	}
+
+	p := akv.RotationPolicy{}
+	if policy != nil {
+		p = *policy
+	}
+
+	for _, name := range []string{"every", "before"} {
+		if !flags.Changed(name) {
+			continue
+		}
+
+		value, _ := flags.GetString(name)
+		dur, err := longduration.ParseDuration(value)
+		if err != nil || dur < 0 {
+			return nil, fmt.Errorf("%w: --%s %q is not a duration", akv.ErrInvalidPolicy, name, value)
+		}
+
+		if name == "every" {
+			p.Every = dur
+		} else {
+			p.Before = dur
+		}
+	}
+
+	if flags.Changed("length") {
+		p.Length, _ = flags.GetInt16("length")
+	}
+
+	if flags.Changed("charset") {
+		p.Charset, _ = flags.GetString("charset")
+		if _, err := p.GenerateOptions(lookupProfile); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.Every <= 0 {
+		return nil, fmt.Errorf("%w: the secret has no %s tag, use --every", akv.ErrInvalidPolicy, akv.TagRotateEvery)
+	}
+
+	return &p, nil
+}
@@ -5,13 +5,12 @@ package cmd
 
 import (
 	"io"
-	"net/url"
 	"os"
 	"strings"
 	"time"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
 	"github.com/hyprxlabs/go/env"
+	"github.com/hyprxlabs/secrets-akv/akv"
 	"github.com/mashiike/longduration"
 	"github.com/spf13/cobra"
 )
@@ -33,193 +32,30 @@ If the URL is not provided, you must specify the vault and key using flags.
 hx-secrets-akv set https://myvault.vault.azure.net/secrets/mykey --value-file myvalue.txt
 hx-secrets-akv set akv://myvault/mykey --value-variable MY_SECRET_VAR
 echo "myvalue" | hx-secrets-akv set --vault myvault --key mykey --stdin
-hx-secrets-akv set --vault myvault --key mykey --expires-at 2025-12-31T23:59:59Z --not-before 2025-01`,
+hx-secrets-akv set --vault myvault --key mykey --expires-at 2025-12-31T23:59:59Z --not-before 2025-01-01T00:00:00Z
+hx-secrets-akv set akv://myvault/mykey --value myvalue --expires-at 90d`,
 	Run: func(cmd *cobra.Command, args []string) {
+		vaultName, key, _ := secretArgs(cmd, args)
+		tags, _ := cmd.Flags().GetStringArray("tag")
 
-		vaultName, _ := cmd.Flags().GetString("vault")
-		key, _ := cmd.Flags().GetString("key")
-		interactive, _ := cmd.Flags().GetBool("interactive")
-		deviceCode, _ := cmd.Flags().GetBool("device-code")
-		quiet, _ := cmd.Flags().GetBool("quiet")
-
-		value, _ := cmd.Flags().GetString("value")
-		valueVar, _ := cmd.Flags().GetString("value-variable")
-		valueFile, _ := cmd.Flags().GetString("value-file")
-		valueStdin, _ := cmd.Flags().GetBool("stdin")
-		expiresAt, _ := cmd.Flags().GetString("expires-at")
-		startsAt, _ := cmd.Flags().GetString("starts-at")
-		tags, _ := cmd.Flags().GetString("tag")
-
-		var valuePtr *string
-		if len(value) > 0 {
-			valuePtr = &value
-		}
-
-		if valueStdin {
-			bytes, err := io.ReadAll(os.Stdin)
-			if err != nil {
-				if !quiet {
-					cmd.PrintErrf("Error reading from stdin: %v\n", err)
-				}
-				os.Exit(1)
-			}
-			value := string(bytes)
-			value = strings.TrimSpace(value)
-			valuePtr = &value
-		}
-
-		if len(valueFile) > 0 {
-			bytes, err := os.ReadFile(valueFile)
-			if err != nil {
-				if !quiet {
-					cmd.PrintErrf("Error reading file %s: %v\n", valueFile, err)
-				}
-				os.Exit(1)
-			}
-			value := string(bytes)
-			if len(value) > 0 {
-				valuePtr = &value
-			}
-		}
-
-		if len(valueVar) > 0 {
-			value := env.Get(valueVar)
-			if value != "" {
-				valuePtr = &value
-			}
-		}
-
-		ur := ""
-		if len(args) > 0 {
-			ur = args[0]
-		}
-
-		if len(ur) > 0 {
-			uri, err := url.Parse(ur)
-			if err != nil {
-				if !quiet {
-					cmd.PrintErrf("Invalid URL: %s\n", ur)
-				}
-				os.Exit(4)
-			}
-
-			vaultName = uri.Host
-			path := uri.Path
-			path = strings.TrimPrefix(path, "/")
-			path = strings.TrimSuffix(path, "/")
-			path = strings.TrimPrefix(path, "secrets/")
-			if len(path) > 0 {
-				if strings.ContainsRune(path, '/') {
-					parts := strings.SplitN(path, "/", 2)
-					key = parts[0]
-				} else {
-					key = path
-				}
-			}
-		}
-
-		if !strings.HasSuffix(vaultName, ".vault.azure.net") {
-			vaultName += ".vault.azure.net"
-		}
-
-		inter := ""
-		if deviceCode {
-			inter = "device-code"
-		}
-
-		if interactive {
-			inter = "interactive"
-		}
-
-		var interactivePtr *string
-		if inter != "" {
-			interactivePtr = &inter
-		}
-
-		creds, err := getCredential(interactivePtr, cmd.Context())
-		if err != nil {
-			if !quiet {
-				cmd.PrintErrf("Failed to get credentials: %v\n", err)
-			}
-			os.Exit(1)
-		}
-
-		client, err := azsecrets.NewClient("https://"+vaultName, creds, nil)
-		if err != nil {
-			if !quiet {
-				cmd.PrintErrf("Failed to create client: %v\n", err)
-			}
-			os.Exit(2)
-		}
-
-		params := &azsecrets.SetSecretParameters{}
-		if valuePtr != nil {
-			params.Value = valuePtr
-		}
-		if len(expiresAt) > 0 {
-			dur, err := longduration.ParseDuration(expiresAt)
-			if err == nil {
-
-				if params.SecretAttributes == nil {
-					params.SecretAttributes = &azsecrets.SecretAttributes{}
-				}
-				dt := time.Now().Add(dur)
-				params.SecretAttributes.Expires = &dt
-			} else {
-				targetTime, err := time.Parse(time.RFC3339, expiresAt)
-				if err == nil {
-					if params.SecretAttributes == nil {
-						params.SecretAttributes = &azsecrets.SecretAttributes{}
-					}
-					params.SecretAttributes.Expires = &targetTime
-				}
-			}
-		}
-
-		if len(startsAt) > 0 {
-			dur, err := longduration.ParseDuration(startsAt)
-			if err == nil {
-				if params.SecretAttributes == nil {
-					params.SecretAttributes = &azsecrets.SecretAttributes{}
-				}
-				dt := time.Now().Add(dur)
-				params.SecretAttributes.NotBefore = &dt
-			} else {
-				targetTime, err := time.Parse(time.RFC3339, startsAt)
-				if err == nil {
-					if params.SecretAttributes == nil {
-						params.SecretAttributes = &azsecrets.SecretAttributes{}
-					}
-					params.SecretAttributes.NotBefore = &targetTime
-				}
-			}
+		value := secretValue(cmd, args)
+		options := &akv.SetOptions{
+			Expires:   timeFlag(cmd, "expires-at"),
+			NotBefore: timeFlag(cmd, "not-before"),
 		}
 
 		if len(tags) > 0 {
-			params.Tags = make(map[string]*string)
-			for _, tag := range strings.Split(tags, ",") {
-				parts := strings.SplitN(tag, "=", 2)
-				if len(parts) == 2 {
-					params.Tags[parts[0]] = &parts[1]
-				} else {
-					params.Tags[parts[0]] = nil
-				}
-			}
+			options.Tags = parseTags(tags)
 		}
 
-		resp, err := client.SetSecret(cmd.Context(), key, *params, nil)
+		client := newClient(cmd, vaultName)
+		secret, err := client.Set(cmd.Context(), key, value, options)
 		if err != nil {
-			if !quiet {
-				cmd.PrintErrf("Failed to set secret: %v\n", err)
-			}
-			os.Exit(1)
-		}
-
-		if !quiet {
-			cmd.Println("Secret set successfully. version: " + resp.ID.Version())
+			exitWithError(cmd, err)
 		}
 
-		os.Exit(0)
+		cmd.Println("Secret set successfully. version: " + secret.Version)
+		os.Exit(CODE_OK)
 	},
 }
 
@@ -240,164 +76,102 @@ hx-secrets-akv set value akv://myvault/mykey --value-variable MY_SECRET_VAR
 echo "myvalue" | hx-secrets-akv set value --vault myvault --key mykey --stdin
 	`,
 	Run: func(cmd *cobra.Command, args []string) {
-		vaultName, _ := cmd.Flags().GetString("vault")
-		key, _ := cmd.Flags().GetString("key")
-		interactive, _ := cmd.Flags().GetBool("interactive")
-		deviceCode, _ := cmd.Flags().GetBool("device-code")
-		logDebug, _ = cmd.Flags().GetBool("debug")
-
-		value, _ := cmd.Flags().GetString("value")
-		valueVar, _ := cmd.Flags().GetString("value-variable")
-		valueFile, _ := cmd.Flags().GetString("value-file")
-		valueStdin, _ := cmd.Flags().GetBool("stdin")
-		var valuePtr *string
-		if len(args) > 1 {
-			valuePtr = &args[1]
-		}
-
-		if len(value) > 0 {
-			if valuePtr != nil {
-				valuePtr = &value
-			}
-		}
+		vaultName, key, _ := secretArgs(cmd, args)
+		value := secretValue(cmd, args)
 
-		if valuePtr == nil && valueStdin {
-			bytes, err := io.ReadAll(os.Stdin)
-			if err != nil {
-				if logDebug {
-					cmd.PrintErrf("Error reading from stdin: %v\n", err)
-				}
-				os.Exit(CODE_ERROR)
-			}
-			value := string(bytes)
-			value = strings.TrimSpace(value)
-			valuePtr = &value
-		}
-
-		if valuePtr == nil && len(valueFile) > 0 {
-			bytes, err := os.ReadFile(valueFile)
-			if err != nil {
-				if logDebug {
-					cmd.PrintErrf("Error reading file %s: %v\n", valueFile, err)
-				}
-				os.Exit(CODE_ERROR)
-			}
-			value := string(bytes)
-			if len(value) > 0 {
-				valuePtr = &value
-			}
+		client := newClient(cmd, vaultName)
+		secret, err := client.Set(cmd.Context(), key, value, nil)
+		if err != nil {
+			exitWithError(cmd, err)
 		}
 
-		if valuePtr == nil && len(valueVar) > 0 {
-			value := env.Get(valueVar)
-			if value != "" {
-				valuePtr = &value
-			}
-		}
+		cmd.Println("Secret set successfully. version: " + secret.Version)
+		os.Exit(CODE_OK)
+	},
+}
 
-		if valuePtr == nil {
-			if !logDebug {
-				cmd.PrintErrf("Value must be specified. Use --value, --value-variable, --value-file, or provide it as an argument.\n")
-			}
+// secretValue reads the secret value from the second argument, --value,
+// stdin, --value-file or --value-variable, in that order. It exits the
+// process when no value is given.
+func secretValue(cmd *cobra.Command, args []string) string {
+	value, _ := cmd.Flags().GetString("value")
+	valueVar, _ := cmd.Flags().GetString("value-variable")
+	valueFile, _ := cmd.Flags().GetString("value-file")
+	valueStdin, _ := cmd.Flags().GetBool("stdin")
+
+	if len(args) > 1 {
+		return args[1]
+	}
+
+	if len(value) > 0 {
+		return value
+	}
+
+	if valueStdin {
+		bytes, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			cmd.PrintErrf("Error reading from stdin: %v\n", err)
 			os.Exit(CODE_ERROR)
 		}
+		return strings.TrimSpace(string(bytes))
+	}
 
-		ur := ""
-		if len(args) > 0 {
-			ur = args[0]
-		}
-
-		if len(ur) > 0 {
-			uri, err := url.Parse(ur)
-			if err != nil {
-				if logDebug {
-					cmd.PrintErrf("Invalid URL: %s\n", ur)
-				}
-				os.Exit(CODE_INVALID_URL)
-			}
-
-			vaultName = uri.Host
-			path := uri.Path
-			path = strings.TrimPrefix(path, "/")
-			path = strings.TrimSuffix(path, "/")
-			path = strings.TrimPrefix(path, "secrets/")
-			if len(path) > 0 {
-				if strings.ContainsRune(path, '/') {
-					parts := strings.SplitN(path, "/", 2)
-					key = parts[0]
-				} else {
-					key = path
-				}
-			}
-		}
-
-		if vaultName == "" {
-			if logDebug {
-				cmd.PrintErrf("Vault name must be specified.\n")
-			}
-			os.Exit(CODE_MISSING_VAULT_NAME)
-		}
-
-		if key == "" {
-			if logDebug {
-				cmd.PrintErrf("Key name must be specified.\n")
-			}
-			os.Exit(CODE_MISSING_VAULT_SECRET_NAME)
-		}
-
-		if !strings.HasSuffix(vaultName, ".vault.azure.net") {
-			vaultName += ".vault.azure.net"
-		}
-
-		inter := ""
-		if deviceCode {
-			inter = "device-code"
-		}
-
-		if interactive {
-			inter = "interactive"
-		}
-
-		var interactivePtr *string
-		if inter != "" {
-			interactivePtr = &inter
-		}
-
-		creds, err := getCredential(interactivePtr, cmd.Context())
+	if len(valueFile) > 0 {
+		bytes, err := os.ReadFile(valueFile)
 		if err != nil {
-			if logDebug {
-				cmd.PrintErrf("Failed to get credentials: %v\n", err)
-			}
-			os.Exit(CODE_INVALID_CREDENTIALS)
+			cmd.PrintErrf("Error reading file %s: %v\n", valueFile, err)
+			os.Exit(CODE_ERROR)
 		}
-
-		client, err := azsecrets.NewClient("https://"+vaultName, creds, nil)
-		if err != nil {
-			if logDebug {
-				cmd.PrintErrf("Failed to create client: %v\n", err)
-			}
-			os.Exit(CODE_CLIENT_CREATION_FAILED)
+		if len(bytes) > 0 {
+			return string(bytes)
 		}
+	}
 
-		params := &azsecrets.SetSecretParameters{}
-		if valuePtr != nil {
-			params.Value = valuePtr
+	if len(valueVar) > 0 {
+		value := env.Get(valueVar)
+		if value != "" {
+			return value
 		}
+	}
 
-		resp, err := client.SetSecret(cmd.Context(), key, *params, nil)
-		if err != nil {
-			if !logDebug {
-				cmd.PrintErrf("Failed to set secret: %v\n", err)
-			}
-			os.Exit(CODE_SECRET_SET_FAILED)
-		}
+	cmd.PrintErrf("Value must be specified. Use --value, --value-variable, --value-file, --stdin or provide it as an argument.\n")
+	os.Exit(CODE_ERROR)
+	return ""
+}
 
-		if !logDebug {
-			cmd.Println("Secret set successfully. version: " + resp.ID.Version())
-		}
+// parseTime parses either a duration from now, such as 90d, or an
+// RFC3339 timestamp. It returns nil when neither format matches.
+func parseTime(value string) *time.Time {
+	dur, err := longduration.ParseDuration(value)
+	if err == nil {
+		dt := time.Now().Add(dur)
+		return &dt
+	}
+
+	targetTime, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return &targetTime
+	}
+
+	return nil
+}
 
-		os.Exit(0)
-	},
+// timeFlag returns the time given with the flag name, nil when it is not
+// set. It exits when the value is neither a duration nor an RFC3339
+// timestamp.
+func timeFlag(cmd *cobra.Command, name string) *time.Time {
+	value, _ := cmd.Flags().GetString(name)
+	if value == "" {
+		return nil
+	}
+
+	t := parseTime(value)
+	if t == nil {
+		cmd.PrintErrf("Invalid --%s value %q\n", name, value)
+		os.Exit(CODE_ERROR)
+	}
+
+	return t
 }
 
 func init() {
@@ -438,3 +212,22 @@ func init() {
 	// is called directly, e.g.:
 	// setCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
+
+// parseTags parses tags given as key=value pairs. Each value may hold
+// several comma separated pairs and a key without a value gets an empty
+// value.
+func parseTags(values []string) map[string]string {
+	tags := map[string]string{}
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			if tag == "" {
+				continue
+			}
+
+			key, value, _ := strings.Cut(tag, "=")
+			tags[key] = value
+		}
+	}
+
+	return tags
+}
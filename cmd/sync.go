@@ -0,0 +1,253 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hyprxlabs/secrets-akv/akv"
+	"github.com/spf13/cobra"
+)
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Copies secrets from one vault to another",
+	Long: `Copies the secrets of one vault, or the secrets matching --query, to
+another vault. Only secrets whose value, tags, content type or expiry differ
+are written; unchanged secrets are left alone. With --prune, secrets in the
+destination that match the query but do not exist in the source are
+deleted.
+
+Disabled source secrets and managed secrets, such as those backing
+certificates, are skipped. Secrets that are disabled in the destination are
+skipped with a warning instead of being enabled again by the copy.
+
+The source and destination may live in different tenants or clouds, or use
+different endpoints. The --from-* and --to-* flags override the global
+flags for one side only.`,
+	Example: `hx-secrets-akv sync --from akv://dev-vault --to akv://staging-vault --query 'shared-*'
+hx-secrets-akv sync --from prod-vault --to prod-dr-vault --prune --dry-run
+hx-secrets-akv sync --from akv://vault-a --to akv://vault-b --to-tenant 00000000-0000-0000-0000-000000000000`,
+	Run: func(cmd *cobra.Command, args []string) {
+		from, _ := cmd.Flags().GetString("from")
+		to, _ := cmd.Flags().GetString("to")
+		query, _ := cmd.Flags().GetString("query")
+		prune, _ := cmd.Flags().GetBool("prune")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+
+		fromVault := syncVault(cmd, from, "from")
+		toVault := syncVault(cmd, to, "to")
+
+		src := newSideClient(cmd, fromVault, "from-")
+		dst := newSideClient(cmd, toVault, "to-")
+
+		srcList, err := src.List(cmd.Context(), query)
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+
+		dstList, err := dst.List(cmd.Context(), query)
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+
+		// every source name counts for --prune, including the disabled and
+		// managed secrets that are not copied.
+		seen := map[string]bool{}
+		srcNames := []string{}
+		for _, props := range srcList {
+			seen[strings.ToLower(props.Name)] = true
+			if props.Enabled && !props.Managed {
+				srcNames = append(srcNames, props.Name)
+			}
+		}
+
+		dstProps := map[string]akv.SecretProperties{}
+		dstNames := []string{}
+		for _, props := range dstList {
+			dstProps[strings.ToLower(props.Name)] = props
+			if props.Enabled && !props.Managed {
+				dstNames = append(dstNames, props.Name)
+			}
+		}
+
+		srcSecrets, err := src.GetMany(cmd.Context(), srcNames, concurrency)
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+
+		dstSecrets, err := dst.GetMany(cmd.Context(), dstNames, concurrency)
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+
+		current := map[string]*akv.Secret{}
+		for _, secret := range dstSecrets {
+			current[strings.ToLower(secret.Name)] = secret
+		}
+
+		created, updated, unchanged, skipped, deleted := 0, 0, 0, 0, 0
+		for _, secret := range srcSecrets {
+			key := strings.ToLower(secret.Name)
+
+			action := "create"
+			if props, ok := dstProps[key]; ok {
+				if !props.Enabled {
+					skipped++
+					cmd.PrintErrf("Warning: skipping %s, it is disabled in %s\n", secret.Name, toVault)
+					continue
+				}
+
+				action = "update"
+				if existing, ok := current[key]; ok && sameSecret(secret, existing) {
+					action = "unchanged"
+				}
+			}
+
+			switch action {
+			case "create":
+				created++
+			case "update":
+				updated++
+			default:
+				unchanged++
+				continue
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%-9s %s\n", action, secret.Name)
+			if dryRun {
+				continue
+			}
+
+			_, err := dst.Set(cmd.Context(), secret.Name, secret.Value, &akv.SetOptions{
+				ContentType: secret.ContentType,
+				NotBefore:   secret.NotBefore,
+				Expires:     secret.Expires,
+				Tags:        secret.Tags,
+			})
+			if err != nil {
+				exitWithError(cmd, err)
+			}
+		}
+
+		if prune {
+			for _, props := range dstList {
+				if seen[strings.ToLower(props.Name)] || props.Managed {
+					continue
+				}
+
+				deleted++
+				fmt.Fprintf(cmd.OutOrStdout(), "%-9s %s\n", "delete", props.Name)
+				if dryRun {
+					continue
+				}
+
+				if err := dst.Delete(cmd.Context(), props.Name, nil); err != nil && !akv.IsNotFound(err) {
+					exitWithError(cmd, err)
+				}
+			}
+		}
+
+		summary := fmt.Sprintf("%d created, %d updated, %d unchanged", created, updated, unchanged)
+		if skipped > 0 {
+			summary += fmt.Sprintf(", %d skipped", skipped)
+		}
+		if prune {
+			summary += fmt.Sprintf(", %d deleted", deleted)
+		}
+		if dryRun {
+			summary = "dry run: " + summary
+		}
+
+		fmt.Fprintln(cmd.OutOrStdout(), summary)
+		os.Exit(CODE_OK)
+	},
+}
+
+// syncVault returns the vault of a --from or --to reference, or the side's
+// endpoint when the reference is empty. It exits when the vault is missing
+// or not valid.
+func syncVault(cmd *cobra.Command, value string, flag string) string {
+	if value == "" {
+		value, _ = cmd.Flags().GetString(flag + "-endpoint")
+		if value == "" {
+			cmd.PrintErrf("Error: --%s is required\n", flag)
+			os.Exit(CODE_MISSING_VAULT_NAME)
+		}
+	}
+
+	vault := value
+	endpoint := prefixedFlagOrEnv(cmd, flag+"-", "endpoint", "HX_AKV_ENDPOINT")
+	if akv.IsEndpointRef(value, endpoint) || strings.Contains(value, "/secrets/") {
+		ref, err := akv.ParseEndpointRef(value, endpoint)
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+		vault = ref.Vault
+	}
+
+	if err := akv.ValidateVaultName(vault); err != nil {
+		exitWithError(cmd, err)
+	}
+
+	return vault
+}
+
+// newSideClient creates a client for one side of a command that talks to
+// two vaults, using the flags with the given prefix.
+func newSideClient(cmd *cobra.Command, vaultName string, prefix string) *akv.Client {
+	creds, options := clientOptions(cmd, vaultName, prefix)
+
+	client, err := akv.NewClient(vaultName, creds, options)
+	if err != nil {
+		exitWithError(cmd, err)
+	}
+
+	return client
+}
+
+// sameSecret reports whether two secrets have the same value, tags, content
+// type and validity period.
+func sameSecret(a, b *akv.Secret) bool {
+	return a.Value == b.Value &&
+		a.ContentType == b.ContentType &&
+		maps.Equal(a.Tags, b.Tags) &&
+		sameTime(a.Expires, b.Expires) &&
+		sameTime(a.NotBefore, b.NotBefore)
+}
+
+// sameTime compares two optional times to the second, which is the
+// precision key vault stores.
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	return a.Unix() == b.Unix()
+}
+
+func init() {
+	syncCmd.Flags().String("from", "", "Source vault, as a name, url or akv://<vault>")
+	syncCmd.Flags().String("to", "", "Destination vault, as a name, url or akv://<vault>")
+	syncCmd.Flags().StringP("query", "s", "", "A query to filter the secrets by name")
+	syncCmd.Flags().Bool("prune", false, "Delete destination secrets that match the query but are not in the source")
+	syncCmd.Flags().Bool("dry-run", false, "Only show what would be created, updated or deleted")
+	syncCmd.Flags().Int("concurrency", akv.DefaultConcurrency, "Number of secrets to read at the same time")
+	for _, side := range []string{"from", "to"} {
+		syncCmd.Flags().String(side+"-tenant", "", "Tenant to authenticate to for the "+side+" vault")
+		syncCmd.Flags().String(side+"-cloud", "", "Azure cloud of the "+side+" vault")
+		syncCmd.Flags().String(side+"-endpoint", "", "Endpoint of the "+side+" vault")
+		syncCmd.Flags().String(side+"-access-token", "", "Static bearer token for the "+side+" vault")
+	}
+	syncCmd.Flags().BoolP("interactive", "i", false, "Use interactive authentication")
+	syncCmd.Flags().Bool("device-code", false, "Use device code authentication")
+
+	rootCmd.AddCommand(syncCmd)
+}
@@ -0,0 +1,207 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/hyprxlabs/secrets-akv/akv"
+	"github.com/spf13/cobra"
+)
+
+// tagsCmd represents the tags command
+var tagsCmd = &cobra.Command{
+	Use:   "tags",
+	Short: "Reads and changes the tags of a secret",
+	Long: `Reads and changes the tags of a secret in place, without creating a new
+version. 'set' merges into the existing tags, 'rm' removes single tags and
+'clear' removes all of them.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+		os.Exit(CODE_OK)
+	},
+}
+
+var tagsGetCmd = &cobra.Command{
+	Use:   "get [url] [tag]",
+	Short: "Prints the tags of a secret",
+	Long: `Prints the tags of a secret as a JSON object, or the value of a single tag
+when its name is given.`,
+	Example: `hx-secrets-akv tags get akv://myvault/db-pass
+hx-secrets-akv tags get akv://myvault/db-pass env
+hx-secrets-akv tags get --vault myvault --key db-pass env`,
+	Args: cobra.MaximumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		vaultName, key, version, names := tagArgs(cmd, args)
+		if len(names) > 1 {
+			cmd.PrintErrf("Error: only one tag name can be given\n")
+			os.Exit(CODE_ERROR)
+		}
+
+		client := newClient(cmd, vaultName)
+		secret, err := client.Get(cmd.Context(), key, version)
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+
+		if len(names) > 0 {
+			value, ok := secret.Tags[names[0]]
+			if !ok {
+				cmd.PrintErrf("Error: tag %s not found\n", names[0])
+				os.Exit(CODE_ERROR)
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), value)
+			os.Exit(CODE_OK)
+		}
+
+		tags := secret.Tags
+		if tags == nil {
+			tags = map[string]string{}
+		}
+
+		bits, err := json.MarshalIndent(tags, "", "  ")
+		if err != nil {
+			cmd.PrintErrf("Error: %v\n", err)
+			os.Exit(CODE_ERROR)
+		}
+
+		fmt.Fprintln(cmd.OutOrStdout(), string(bits))
+		os.Exit(CODE_OK)
+	},
+}
+
+var tagsSetCmd = &cobra.Command{
+	Use:   "set [url]",
+	Short: "Sets tags of a secret, keeping the other tags",
+	Long: `Sets tags of a secret given with -t key=value flags, as a JSON object with
+--json, or both. Existing tags that are not given are kept unless --replace
+is set.`,
+	Example: `hx-secrets-akv tags set akv://myvault/db-pass -t env=prod -t team=api
+hx-secrets-akv tags set akv://myvault/db-pass --json '{"env":"prod","owner":"ops"}'
+hx-secrets-akv tags set akv://myvault/db-pass -t env=prod --replace`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		vaultName, key, version := secretArgs(cmd, args)
+		tagFlags, _ := cmd.Flags().GetStringArray("tag")
+		jsonTags, _ := cmd.Flags().GetString("json")
+		replace, _ := cmd.Flags().GetBool("replace")
+
+		tags := parseTags(tagFlags)
+		if jsonTags != "" {
+			data := map[string]any{}
+			if err := json.Unmarshal([]byte(jsonTags), &data); err != nil {
+				cmd.PrintErrf("Error: --json must be a JSON object: %v\n", err)
+				os.Exit(CODE_ERROR)
+			}
+
+			values, err := stringValues(data)
+			if err != nil {
+				cmd.PrintErrf("Error: %v\n", err)
+				os.Exit(CODE_ERROR)
+			}
+
+			for k, v := range values {
+				tags[k] = v
+			}
+		}
+
+		if len(tags) == 0 {
+			cmd.PrintErrf("Error: no tags given, use -t key=value or --json\n")
+			os.Exit(CODE_ERROR)
+		}
+
+		client := newClient(cmd, vaultName)
+
+		var err error
+		if replace {
+			_, err = client.Update(cmd.Context(), key, version, &akv.UpdateOptions{Tags: tags})
+		} else {
+			_, err = client.MergeTags(cmd.Context(), key, version, tags, nil)
+		}
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+
+		cmd.Println("Tags updated.")
+		os.Exit(CODE_OK)
+	},
+}
+
+var tagsRemoveCmd = &cobra.Command{
+	Use:     "rm [url] <tag>...",
+	Aliases: []string{"remove"},
+	Short:   "Removes tags from a secret",
+	Example: `hx-secrets-akv tags rm akv://myvault/db-pass env team
+hx-secrets-akv tags rm --vault myvault --key db-pass env -t team`,
+	Run: func(cmd *cobra.Command, args []string) {
+		vaultName, key, version, rest := tagArgs(cmd, args)
+		names, _ := cmd.Flags().GetStringArray("tag")
+		names = append(names, rest...)
+
+		if len(names) == 0 {
+			cmd.PrintErrf("Error: no tags given\n")
+			os.Exit(CODE_ERROR)
+		}
+
+		client := newClient(cmd, vaultName)
+		if _, err := client.MergeTags(cmd.Context(), key, version, nil, names); err != nil {
+			exitWithError(cmd, err)
+		}
+
+		cmd.Println("Tags removed.")
+		os.Exit(CODE_OK)
+	},
+}
+
+var tagsClearCmd = &cobra.Command{
+	Use:     "clear [url]",
+	Short:   "Removes every tag from a secret",
+	Example: `hx-secrets-akv tags clear akv://myvault/db-pass`,
+	Args:    cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		vaultName, key, version := secretArgs(cmd, args)
+
+		client := newClient(cmd, vaultName)
+		if _, err := client.ClearTags(cmd.Context(), key, version); err != nil {
+			exitWithError(cmd, err)
+		}
+
+		cmd.Println("Tags cleared.")
+		os.Exit(CODE_OK)
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{tagsGetCmd, tagsSetCmd, tagsRemoveCmd, tagsClearCmd} {
+		c.Flags().StringP("vault", "v", "", "Key Vault name (e.g., myvault)")
+		c.Flags().StringP("key", "k", "", "Key name in the Key Vault")
+		c.Flags().StringP("version", "V", "", "Version of the secret (default the current version)")
+		c.Flags().BoolP("interactive", "i", false, "Use interactive authentication")
+		c.Flags().Bool("device-code", false, "Use device code authentication")
+		tagsCmd.AddCommand(c)
+	}
+
+	tagsSetCmd.Flags().StringArrayP("tag", "t", []string{}, "Tag in key=value format. Can be repeated or comma separated.")
+	tagsSetCmd.Flags().String("json", "", "Tags as a JSON object")
+	tagsSetCmd.Flags().Bool("replace", false, "Replace all existing tags instead of merging")
+	tagsRemoveCmd.Flags().StringArrayP("tag", "t", []string{}, "Name of a tag to remove. Can be repeated.")
+
+	rootCmd.AddCommand(tagsCmd)
+}
+
+// tagArgs returns the secret from the reference argument, or from the flags
+// when the first argument is not a reference, and the remaining arguments
+// as tag names.
+func tagArgs(cmd *cobra.Command, args []string) (vaultName, key, version string, names []string) {
+	if len(args) > 0 && akv.IsEndpointRef(args[0], flagOrEnv(cmd, "endpoint", "HX_AKV_ENDPOINT")) {
+		vaultName, key, version = secretArgs(cmd, args[:1])
+		return vaultName, key, version, args[1:]
+	}
+
+	vaultName, key, version = secretArgs(cmd, nil)
+	return vaultName, key, version, args
+}
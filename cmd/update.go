@@ -0,0 +1,115 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"os"
+
+	"github.com/hyprxlabs/secrets-akv/akv"
+	"github.com/spf13/cobra"
+)
+
+// updateCmd represents the update command
+var updateCmd = &cobra.Command{
+	Use:     "update [url]",
+	Aliases: []string{"props"},
+	Short:   "Updates the properties of a secret without creating a new version",
+	Long: `Updates whether a secret is enabled, its expiry, start time, content type or
+tags in place. Unlike 'set', no new version is created and no value is
+needed.
+
+The current version is updated unless --version is given. --tag replaces
+every tag of the version; use --clear-tags to remove them all, or the 'tags'
+commands to change single tags.`,
+	Example: `hx-secrets-akv update akv://myvault/db-pass --expires-at 90d
+hx-secrets-akv update --vault myvault --key db-pass --version 5597107f635f00c5df4ebe476dd15658 --disable
+hx-secrets-akv props akv://myvault/db-pass --content-type text/plain --tag env=prod --tag team=api`,
+	Run: func(cmd *cobra.Command, args []string) {
+		vaultName, key, version := secretArgs(cmd, args)
+		enable, _ := cmd.Flags().GetBool("enable")
+		disable, _ := cmd.Flags().GetBool("disable")
+		expiresAt, _ := cmd.Flags().GetString("expires-at")
+		notBefore, _ := cmd.Flags().GetString("not-before")
+		tags, _ := cmd.Flags().GetStringArray("tag")
+		clearTags, _ := cmd.Flags().GetBool("clear-tags")
+
+		if enable && disable {
+			cmd.PrintErrf("Error: --enable and --disable cannot be used together\n")
+			os.Exit(CODE_ERROR)
+		}
+
+		options := &akv.UpdateOptions{}
+		changed := false
+
+		if enable || disable {
+			enabled := enable
+			options.Enabled = &enabled
+			changed = true
+		}
+
+		if expiresAt != "" {
+			options.Expires = parseTime(expiresAt)
+			if options.Expires == nil {
+				cmd.PrintErrf("Invalid --expires-at value %q\n", expiresAt)
+				os.Exit(CODE_ERROR)
+			}
+			changed = true
+		}
+
+		if notBefore != "" {
+			options.NotBefore = parseTime(notBefore)
+			if options.NotBefore == nil {
+				cmd.PrintErrf("Invalid --not-before value %q\n", notBefore)
+				os.Exit(CODE_ERROR)
+			}
+			changed = true
+		}
+
+		if cmd.Flags().Changed("content-type") {
+			contentType, _ := cmd.Flags().GetString("content-type")
+			options.ContentType = &contentType
+			changed = true
+		}
+
+		if len(tags) > 0 {
+			options.Tags = parseTags(tags)
+			changed = true
+		} else if clearTags {
+			options.Tags = map[string]string{}
+			changed = true
+		}
+
+		if !changed {
+			cmd.PrintErrf("Error: nothing to update, use --enable, --disable, --expires-at, --not-before, --content-type, --tag or --clear-tags\n")
+			os.Exit(CODE_ERROR)
+		}
+
+		client := newClient(cmd, vaultName)
+
+		props, err := client.Update(cmd.Context(), key, version, options)
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+
+		cmd.Println("Secret updated. version: " + props.Version)
+		os.Exit(CODE_OK)
+	},
+}
+
+func init() {
+	updateCmd.Flags().StringP("vault", "v", "", "Key Vault name (e.g., myvault)")
+	updateCmd.Flags().StringP("key", "k", "", "Key name in the Key Vault")
+	updateCmd.Flags().StringP("version", "V", "", "Version of the secret to update (default the current version)")
+	updateCmd.Flags().Bool("enable", false, "Enable the version")
+	updateCmd.Flags().Bool("disable", false, "Disable the version")
+	updateCmd.Flags().StringP("expires-at", "e", "", "Expiration time of the secret (RFC3339 or duration format)")
+	updateCmd.Flags().StringP("not-before", "b", "", "Start time of the secret (RFC3339 or duration format)")
+	updateCmd.Flags().String("content-type", "", "Content type of the secret")
+	updateCmd.Flags().StringArrayP("tag", "t", []string{}, "Tags in key=value format, replacing the existing tags. Can be repeated.")
+	updateCmd.Flags().Bool("clear-tags", false, "Remove every tag")
+	updateCmd.Flags().BoolP("interactive", "i", false, "Use interactive authentication")
+	updateCmd.Flags().Bool("device-code", false, "Use device code authentication")
+
+	rootCmd.AddCommand(updateCmd)
+}
@@ -2,18 +2,33 @@ package cmd
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/hyprxlabs/go/dotenv"
 	"github.com/hyprxlabs/go/env"
+	"github.com/hyprxlabs/secrets-akv/akv"
+	"github.com/spf13/cobra"
 )
 
-func getCredential(interactive *string, ctx context.Context) (azcore.TokenCredential, error) {
+var loadConfigOnce sync.Once
 
+// loadConfig copies the values of the configuration env file into the
+// process environment without overwriting variables that are already set.
+func loadConfig() {
+	loadConfigOnce.Do(readConfig)
+}
+
+func readConfig() {
 	targetDir := homeConfigDir()
 	if targetDir == "" {
 		targetDir = osConfigDir()
@@ -77,20 +92,33 @@ func getCredential(interactive *string, ctx context.Context) (azcore.TokenCreden
 			}
 		}
 	}
+}
+
+// getCredential creates the credential chain. When tenant is set, tokens
+// are requested from that tenant instead of the default one.
+func getCredential(interactive *string, ctx context.Context, cloudCfg cloud.Configuration, tenant string) (azcore.TokenCredential, error) {
+	loadConfig()
+
+	clientOptions := azcore.ClientOptions{Cloud: cloudCfg}
 
 	useIdentityRaw := env.Get("AZURE_IDENTITY")
 	if strings.EqualFold(useIdentityRaw, "true") || strings.EqualFold(useIdentityRaw, "1") {
 		clientId := env.Get("AZURE_CLIENT_ID")
 		if clientId == "" {
-			return azidentity.NewManagedIdentityCredential(nil)
+			return azidentity.NewManagedIdentityCredential(&azidentity.ManagedIdentityCredentialOptions{
+				ClientOptions: clientOptions,
+			})
 		}
 
 		return azidentity.NewManagedIdentityCredential(&azidentity.ManagedIdentityCredentialOptions{
-			ID: azidentity.ClientID(clientId),
+			ClientOptions: clientOptions,
+			ID:            azidentity.ClientID(clientId),
 		})
 	}
 
-	azCliCredential, err3 := azidentity.NewAzureCLICredential(nil)
+	azCliCredential, err3 := azidentity.NewAzureCLICredential(&azidentity.AzureCLICredentialOptions{
+		TenantID: tenant,
+	})
 	if err3 != nil {
 		return nil, err3
 	}
@@ -99,7 +127,18 @@ func getCredential(interactive *string, ctx context.Context) (azcore.TokenCreden
 
 	if (env.Has("AZURE_TENANT_ID") && env.Has("AZURE_CLIENT_ID")) && env.Has("AZURE_CLIENT_SECRET") || env.Has("AZURE_CLIENT_CERTIFICATE_PATH") {
 		println("Using environment credentials")
-		envCredentials, err1 := azidentity.NewEnvironmentCredential(nil)
+		var envCredentials azcore.TokenCredential
+		var err1 error
+		if tenant != "" && env.Has("AZURE_CLIENT_SECRET") {
+			// the same multi-tenant application, signed in to another tenant.
+			envCredentials, err1 = azidentity.NewClientSecretCredential(tenant, env.Get("AZURE_CLIENT_ID"), env.Get("AZURE_CLIENT_SECRET"), &azidentity.ClientSecretCredentialOptions{
+				ClientOptions: clientOptions,
+			})
+		} else {
+			envCredentials, err1 = azidentity.NewEnvironmentCredential(&azidentity.EnvironmentCredentialOptions{
+				ClientOptions: clientOptions,
+			})
+		}
 		if err1 != nil {
 			return nil, err1
 		}
@@ -111,7 +150,7 @@ func getCredential(interactive *string, ctx context.Context) (azcore.TokenCreden
 	}
 
 	if interactive != nil && *interactive == "device-code" {
-		creds, err := newDeviceCode(ctx)
+		creds, err := newDeviceCode(ctx, clientOptions, tenant)
 		if err != nil {
 			return nil, err
 		}
@@ -122,7 +161,7 @@ func getCredential(interactive *string, ctx context.Context) (azcore.TokenCreden
 			os.Exit(6)
 		}
 	} else if interactive != nil && *interactive == "interactive" {
-		creds, err := newAzInteractive(ctx)
+		creds, err := newAzInteractive(ctx, clientOptions, tenant)
 		if err != nil {
 			return nil, err
 		}
@@ -135,3 +174,243 @@ func getCredential(interactive *string, ctx context.Context) (azcore.TokenCreden
 
 	return azidentity.NewChainedTokenCredential(credentials, nil)
 }
+
+// secretArgs returns the vault, key and version from the command flags,
+// replaced by the parts of the secret reference argument when one is given.
+// It exits the process when the resulting reference is not valid.
+func secretArgs(cmd *cobra.Command, args []string) (vaultName, key, version string) {
+	ref := secretRef(cmd, args)
+	if ref.Vault == "" {
+		// with an endpoint override the vault name is optional.
+		ref.Vault = flagOrEnv(cmd, "endpoint", "HX_AKV_ENDPOINT")
+	}
+
+	if err := ref.Validate(); err != nil {
+		exitWithError(cmd, err)
+	}
+
+	return ref.Vault, ref.Name, ref.Version
+}
+
+// secretRef builds a secret reference from the vault, key and version
+// flags and the optional reference argument without validating it.
+func secretRef(cmd *cobra.Command, args []string) akv.SecretRef {
+	ref := akv.SecretRef{}
+	ref.Vault, _ = cmd.Flags().GetString("vault")
+	ref.Name, _ = cmd.Flags().GetString("key")
+	ref.Version, _ = cmd.Flags().GetString("version")
+
+	if len(args) == 0 || len(args[0]) == 0 {
+		return ref
+	}
+
+	parsed, err := akv.ParseEndpointRef(args[0], flagOrEnv(cmd, "endpoint", "HX_AKV_ENDPOINT"))
+	if err != nil {
+		exitWithError(cmd, err)
+	}
+
+	ref.Vault = parsed.Vault
+	if parsed.Name != "" {
+		ref.Name = parsed.Name
+	}
+	if parsed.Version != "" {
+		ref.Version = parsed.Version
+	}
+
+	return ref
+}
+
+// newClient creates a key vault client using the authentication flags of
+// the command. It exits the process when the client cannot be created.
+func newClient(cmd *cobra.Command, vaultName string) *akv.Client {
+	endpoint := flagOrEnv(cmd, "endpoint", "HX_AKV_ENDPOINT")
+	if vaultName == "" && endpoint == "" {
+		exitWithError(cmd, akv.ErrMissingVaultName)
+	}
+
+	creds, options := clientOptions(cmd, vaultName, "")
+
+	client, err := akv.NewClient(vaultName, creds, options)
+	if err != nil {
+		exitWithError(cmd, err)
+	}
+
+	return client
+}
+
+// newResolver creates a resolver for secret references to any vault using
+// the authentication flags of the command. It exits the process when the
+// credentials cannot be created.
+func newResolver(cmd *cobra.Command) *akv.Resolver {
+	creds, options := clientOptions(cmd, "", "")
+	return akv.NewResolver(creds, options)
+}
+
+// clientOptions builds the credential and client options from the
+// authentication, cloud and endpoint flags of the command. With a prefix,
+// such as "from-", the prefixed tenant, cloud, endpoint and access-token
+// flags win over the global ones so that commands can talk to vaults with
+// different credentials. It exits the process on failure.
+func clientOptions(cmd *cobra.Command, vaultName string, prefix string) (azcore.TokenCredential, *akv.ClientOptions) {
+	interactive, _ := cmd.Flags().GetBool("interactive")
+	deviceCode, _ := cmd.Flags().GetBool("device-code")
+
+	inter := ""
+	if deviceCode {
+		inter = "device-code"
+	}
+
+	if interactive {
+		inter = "interactive"
+	}
+
+	var interactivePtr *string
+	if inter != "" {
+		interactivePtr = &inter
+	}
+
+	endpoint := prefixedFlagOrEnv(cmd, prefix, "endpoint", "HX_AKV_ENDPOINT")
+	tenant, _ := cmd.Flags().GetString(prefix + "tenant")
+
+	azCloud, err := resolveCloud(cmd, vaultName, prefix)
+	if err != nil {
+		cmd.PrintErrf("Error: %v\n", err)
+		os.Exit(CODE_ERROR)
+	}
+
+	options := &akv.ClientOptions{Cloud: azCloud, Endpoint: endpoint}
+
+	caFile := flagOrEnv(cmd, "ca-file", "HX_AKV_CA_FILE")
+	if caFile != "" {
+		transport, err := newTransport(caFile)
+		if err != nil {
+			cmd.PrintErrf("Failed to load CA file %s: %v\n", caFile, err)
+			os.Exit(CODE_CLIENT_CREATION_FAILED)
+		}
+		options.Transport = transport
+	}
+
+	var creds azcore.TokenCredential
+	if token := prefixedFlagOrEnv(cmd, prefix, "access-token", "HX_AKV_ACCESS_TOKEN"); token != "" {
+		// a static token is used against stand-in servers whose challenge
+		// resource does not match their host name.
+		creds = akv.NewStaticTokenCredential(token)
+		options.DisableChallengeResourceVerification = true
+	} else {
+		creds, err = getCredential(interactivePtr, cmd.Context(), azCloud.Configuration, tenant)
+		if err != nil {
+			cmd.PrintErrf("Failed to get credentials: %v\n", err)
+			os.Exit(CODE_INVALID_CREDENTIALS)
+		}
+	}
+
+	return creds, options
+}
+
+// flagOrEnv returns the value of the flag, or of the environment variable
+// when the flag is not set. The configuration file is loaded first so that
+// its values are visible as environment variables.
+func flagOrEnv(cmd *cobra.Command, flag, variable string) string {
+	loadConfig()
+	value, _ := cmd.Flags().GetString(flag)
+	if value == "" {
+		value = env.Get(variable)
+	}
+
+	return value
+}
+
+// prefixedFlagOrEnv returns the value of the prefixed flag when the command
+// has it and it is set, and falls back to flagOrEnv otherwise.
+func prefixedFlagOrEnv(cmd *cobra.Command, prefix, flag, variable string) string {
+	if prefix != "" {
+		if value, _ := cmd.Flags().GetString(prefix + flag); value != "" {
+			return value
+		}
+	}
+
+	return flagOrEnv(cmd, flag, variable)
+}
+
+// newTransport creates an http client that trusts the certificates in
+// caFile in addition to the system roots.
+func newTransport(caFile string) (*http.Client, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("no certificates found")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	return &http.Client{Transport: transport}, nil
+}
+
+// resolveCloud selects the cloud for a vault. Host names of a known cloud
+// win over the --cloud flag, which wins over the HX_AKV_CLOUD variable
+// from the environment or configuration.
+func resolveCloud(cmd *cobra.Command, vaultName string, prefix string) (akv.Cloud, error) {
+	if c, ok := akv.CloudFromHost(vaultName); ok {
+		return c, nil
+	}
+
+	name := prefixedFlagOrEnv(cmd, prefix, "cloud", "HX_AKV_CLOUD")
+
+	return akv.ParseCloud(name)
+}
+
+// exitCode maps an error returned by the akv package to an exit code.
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return CODE_OK
+	case errors.Is(err, akv.ErrMissingVaultName):
+		return CODE_MISSING_VAULT_NAME
+	case errors.Is(err, akv.ErrMissingSecretName):
+		return CODE_MISSING_VAULT_SECRET_NAME
+	case errors.Is(err, akv.ErrInvalidURL):
+		return CODE_INVALID_URL
+	case errors.Is(err, akv.ErrClientFailed):
+		return CODE_CLIENT_CREATION_FAILED
+	case errors.Is(err, akv.ErrSecretNotFound):
+		return CODE_SECRET_NOT_FOUND
+	case errors.Is(err, akv.ErrSecretExpired):
+		return CODE_SECRET_EXPIRED
+	case errors.Is(err, akv.ErrGenerateFailed):
+		return CODE_SECRET_GENERATE_FAILED
+	case errors.Is(err, akv.ErrHookFailed):
+		return CODE_SECRET_ROTATION_FAILED
+	}
+
+	var akvErr *akv.Error
+	if errors.As(err, &akvErr) {
+		switch akvErr.Op {
+		case "get", "backup":
+			return CODE_SECRET_GET_FAILED
+		case "set", "restore", "recover", "update", "rollback":
+			return CODE_SECRET_SET_FAILED
+		case "list", "versions":
+			return CODE_SECRET_LIST_FAILED
+		case "delete", "purge":
+			return CODE_SECRET_REMOVE_FAILED
+		case "rotate":
+			return CODE_SECRET_ROTATION_FAILED
+		}
+	}
+
+	return CODE_ERROR
+}
+
+// exitWithError prints err and exits with the matching exit code.
+func exitWithError(cmd *cobra.Command, err error) {
+	cmd.PrintErrf("Error: %v\n", err)
+	os.Exit(exitCode(err))
+}
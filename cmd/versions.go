@@ -0,0 +1,152 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyprxlabs/secrets-akv/akv"
+	"github.com/spf13/cobra"
+)
+
+// SecretVersion is the JSON output of one version of a secret.
+type SecretVersion struct {
+	Version     string            `json:"version"`
+	Value       *string           `json:"value,omitempty"`
+	Enabled     bool              `json:"enabled"`
+	CreatedAt   string            `json:"created_at,omitempty"`
+	UpdatedAt   string            `json:"updated_at,omitempty"`
+	ExpiresAt   string            `json:"expires_at,omitempty"`
+	StartsAt    string            `json:"starts_at,omitempty"`
+	ContentType string            `json:"content_type,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+// versionsCmd represents the versions command
+var versionsCmd = &cobra.Command{
+	Use:   "versions [url]",
+	Short: "Lists the versions of a secret",
+	Long: `Lists every version of a secret, newest first, with its creation and update
+time, whether it is enabled, its expiry and its tags.
+
+Use --values to also read the value of each enabled version, and --output
+to print json, jsonl, yaml or tsv instead of a table.`,
+	Example: `hx-secrets-akv versions akv://myvault/db-pass
+hx-secrets-akv versions --vault myvault --key db-pass --output json --values`,
+	Run: func(cmd *cobra.Command, args []string) {
+		vaultName, key, _ := secretArgs(cmd, args)
+		values, _ := cmd.Flags().GetBool("values")
+
+		client := newClient(cmd, vaultName)
+
+		list, err := client.Versions(cmd.Context(), key)
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+
+		if len(list) == 0 {
+			exitWithError(cmd, &akv.Error{Op: "versions", Vault: client.Vault(), Name: key, Kind: akv.ErrSecretNotFound})
+		}
+
+		versions := make([]SecretVersion, 0, len(list))
+		for _, props := range list {
+			version := newSecretVersion(props)
+			if values && props.Enabled {
+				secret, err := client.Get(cmd.Context(), key, props.Version)
+				if err != nil {
+					exitWithError(cmd, err)
+				}
+				version.Value = &secret.Value
+			}
+
+			versions = append(versions, version)
+		}
+
+		columns := []outputColumn{
+			{"VERSION", func(i int) string { return versions[i].Version }},
+			{"CREATED", func(i int) string { return versions[i].CreatedAt }},
+			{"UPDATED", func(i int) string { return versions[i].UpdatedAt }},
+			{"ENABLED", func(i int) string { return fmt.Sprint(versions[i].Enabled) }},
+			{"EXPIRES", func(i int) string { return versions[i].ExpiresAt }},
+			{"TAGS", func(i int) string { return formatTags(versions[i].Tags) }},
+		}
+		if values {
+			columns = append(columns, outputColumn{"VALUE", func(i int) string {
+				if versions[i].Value == nil {
+					return ""
+				}
+				return *versions[i].Value
+			}})
+		}
+
+		items := make([]any, len(versions))
+		for i, v := range versions {
+			items[i] = v
+		}
+
+		printOutput(cmd, "table", outputData{Items: items, Columns: columns})
+
+		os.Exit(CODE_OK)
+	},
+}
+
+func newSecretVersion(props akv.SecretProperties) SecretVersion {
+	return SecretVersion{
+		Version:     props.Version,
+		Enabled:     props.Enabled,
+		CreatedAt:   formatTime(props.Created),
+		UpdatedAt:   formatTime(props.Updated),
+		ExpiresAt:   formatTime(props.Expires),
+		StartsAt:    formatTime(props.NotBefore),
+		ContentType: props.ContentType,
+		Tags:        props.Tags,
+	}
+}
+
+// formatTime formats an optional time as RFC3339 in UTC.
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatTags formats tags as comma separated key=value pairs sorted by key.
+func formatTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+tags[k])
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
+
+func init() {
+	versionsCmd.Flags().StringP("vault", "v", "", "Key Vault name (e.g., myvault)")
+	versionsCmd.Flags().StringP("key", "k", "", "Key name in the Key Vault")
+	versionsCmd.Flags().Bool("values", false, "Include the value of each enabled version")
+	versionsCmd.Flags().BoolP("interactive", "i", false, "Use interactive authentication")
+	versionsCmd.Flags().Bool("device-code", false, "Use device code authentication")
+
+	rootCmd.AddCommand(versionsCmd)
+}
@@ -0,0 +1,216 @@
+package emulator_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyprxlabs/secrets-akv/akv"
+	"github.com/hyprxlabs/secrets-akv/emulator"
+)
+
+// newTestClient starts an emulator that accepts token on a httptest TLS
+// server and returns an akv client talking to it through the azure sdk.
+func newTestClient(t *testing.T, token string) *akv.Client {
+	t.Helper()
+
+	store, err := emulator.OpenStore(filepath.Join(t.TempDir(), "secrets.json"))
+	if err != nil {
+		t.Fatalf("OpenStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	srv := httptest.NewTLSServer(emulator.NewServer(store, &emulator.Options{Token: token}))
+	t.Cleanup(srv.Close)
+
+	options := &akv.ClientOptions{Endpoint: srv.URL}
+	options.Transport = srv.Client()
+	options.DisableChallengeResourceVerification = true
+
+	client, err := akv.NewClient("", akv.NewStaticTokenCredential("test-token"), options)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	return client
+}
+
+func TestClientEndToEnd(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t, "test-token")
+
+	first, err := client.Set(ctx, "db-pass", "one", &akv.SetOptions{
+		ContentType: "text/plain",
+		Tags:        map[string]string{"env": "test"},
+	})
+	if err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	second, err := client.Set(ctx, "db-pass", "two", nil)
+	if err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if _, err := client.Set(ctx, "api-key", "key", nil); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	t.Run("get", func(t *testing.T) {
+		secret, err := client.Get(ctx, "db-pass", "")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if secret.Value != "two" || secret.Version != second.Version {
+			t.Errorf("Get latest = %q (%s), want %q (%s)", secret.Value, secret.Version, "two", second.Version)
+		}
+
+		old, err := client.Get(ctx, "db-pass", first.Version)
+		if err != nil {
+			t.Fatalf("Get version: %v", err)
+		}
+		if old.Value != "one" || old.ContentType != "text/plain" || old.Tag("env") != "test" {
+			t.Errorf("Get version = %+v", old)
+		}
+
+		if _, err := client.Get(ctx, "missing", ""); !akv.IsNotFound(err) {
+			t.Errorf("Get missing error = %v, want not found", err)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		list, err := client.List(ctx, "db-*")
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(list) != 1 || list[0].Name != "db-pass" {
+			t.Errorf("List db-* = %+v, want db-pass", list)
+		}
+
+		all, err := client.List(ctx, "")
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(all) != 2 {
+			t.Errorf("List returned %d secrets, want 2", len(all))
+		}
+	})
+
+	t.Run("versions", func(t *testing.T) {
+		versions, err := client.Versions(ctx, "db-pass")
+		if err != nil {
+			t.Fatalf("Versions: %v", err)
+		}
+		if len(versions) != 2 {
+			t.Fatalf("Versions returned %d versions, want 2", len(versions))
+		}
+
+		seen := map[string]bool{}
+		for _, v := range versions {
+			seen[v.Version] = true
+		}
+		if !seen[first.Version] || !seen[second.Version] {
+			t.Errorf("Versions = %+v, want %s and %s", versions, first.Version, second.Version)
+		}
+	})
+
+	t.Run("delete and recover", func(t *testing.T) {
+		if err := client.Delete(ctx, "api-key", nil); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+
+		if _, err := client.Get(ctx, "api-key", ""); !akv.IsNotFound(err) {
+			t.Errorf("Get deleted error = %v, want not found", err)
+		}
+
+		deleted, err := client.ListDeleted(ctx, "")
+		if err != nil {
+			t.Fatalf("ListDeleted: %v", err)
+		}
+		if len(deleted) != 1 || deleted[0].Name != "api-key" {
+			t.Errorf("ListDeleted = %+v, want api-key", deleted)
+		}
+
+		secret, err := client.Recover(ctx, "api-key", nil)
+		if err != nil {
+			t.Fatalf("Recover: %v", err)
+		}
+		if secret.Value != "key" {
+			t.Errorf("Recover value = %q, want %q", secret.Value, "key")
+		}
+	})
+
+	t.Run("recover disabled", func(t *testing.T) {
+		disabled := false
+		if _, err := client.Update(ctx, "api-key", "", &akv.UpdateOptions{Enabled: &disabled}); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if err := client.Delete(ctx, "api-key", nil); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+
+		secret, err := client.Recover(ctx, "api-key", nil)
+		if err != nil {
+			t.Fatalf("Recover: %v", err)
+		}
+		if secret.Name != "api-key" || secret.Value != "" {
+			t.Errorf("Recover = %+v, want api-key without a value", secret)
+		}
+	})
+
+	t.Run("backup, purge and restore", func(t *testing.T) {
+		blob, err := client.Backup(ctx, "db-pass")
+		if err != nil {
+			t.Fatalf("Backup: %v", err)
+		}
+
+		if _, err := client.Restore(ctx, blob); !akv.IsConflict(err) {
+			t.Errorf("Restore over an existing secret error = %v, want conflict", err)
+		}
+
+		if err := client.Delete(ctx, "db-pass", &akv.DeleteOptions{Purge: true}); err != nil {
+			t.Fatalf("Delete with purge: %v", err)
+		}
+
+		deleted, err := client.ListDeleted(ctx, "db-*")
+		if err != nil {
+			t.Fatalf("ListDeleted: %v", err)
+		}
+		if len(deleted) != 0 {
+			t.Errorf("ListDeleted after purge = %+v, want none", deleted)
+		}
+
+		props, err := client.Restore(ctx, blob)
+		if err != nil {
+			t.Fatalf("Restore: %v", err)
+		}
+		if props.Name != "db-pass" {
+			t.Errorf("Restore name = %q, want db-pass", props.Name)
+		}
+
+		versions, err := client.Versions(ctx, "db-pass")
+		if err != nil {
+			t.Fatalf("Versions: %v", err)
+		}
+		if len(versions) != 2 {
+			t.Errorf("restored %d versions, want 2", len(versions))
+		}
+
+		again, err := client.Backup(ctx, "db-pass")
+		if err != nil {
+			t.Fatalf("Backup: %v", err)
+		}
+		if len(again) == 0 {
+			t.Error("Backup of the restored secret is empty")
+		}
+	})
+}
+
+func TestClientRejectsWrongToken(t *testing.T) {
+	client := newTestClient(t, "other-token")
+
+	if _, err := client.Set(context.Background(), "x", "y", nil); err == nil {
+		t.Error("Set with a wrong token succeeded")
+	}
+}
@@ -0,0 +1,546 @@
+// Package emulator serves the secrets part of the Azure Key Vault REST API
+// from a local file so that the azsecrets client, and with it every
+// hx-secrets-akv command, can be used without a real vault.
+package emulator
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTenant is the tenant advertised in the bearer challenge.
+	DefaultTenant = "00000000-0000-0000-0000-000000000000"
+	// DefaultResource is the resource advertised in the bearer challenge.
+	DefaultResource = "https://vault.azure.net"
+
+	recoveryLevel   = "Recoverable+Purgeable"
+	recoverableDays = 90
+)
+
+// Options contains optional settings for Server.
+type Options struct {
+	// Token, when set, is the only bearer token accepted. Any token is
+	// accepted when empty.
+	Token string
+	// Tenant and Resource are advertised in the bearer challenge.
+	Tenant   string
+	Resource string
+	// RetentionDays is the number of days a deleted secret is kept before
+	// its scheduled purge date.
+	RetentionDays int
+}
+
+// Server is an http.Handler implementing the key vault secrets API.
+type Server struct {
+	store   Store
+	options Options
+	mu      sync.Mutex
+	mux     *http.ServeMux
+	now     func() time.Time
+}
+
+// NewServer creates a server backed by store.
+func NewServer(store Store, options *Options) *Server {
+	s := &Server{store: store, now: time.Now}
+	if options != nil {
+		s.options = *options
+	}
+
+	if s.options.Tenant == "" {
+		s.options.Tenant = DefaultTenant
+	}
+
+	if s.options.Resource == "" {
+		s.options.Resource = DefaultResource
+	}
+
+	if s.options.RetentionDays <= 0 {
+		s.options.RetentionDays = recoverableDays
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /secrets", s.listSecrets)
+	mux.HandleFunc("GET /secrets/{$}", s.listSecrets)
+	mux.HandleFunc("PUT /secrets/{name}", s.setSecret)
+	mux.HandleFunc("DELETE /secrets/{name}", s.deleteSecret)
+	mux.HandleFunc("GET /secrets/{name}", s.getSecret)
+	mux.HandleFunc("GET /secrets/{name}/{$}", s.getSecret)
+	mux.HandleFunc("GET /secrets/{name}/versions", s.listVersions)
+	mux.HandleFunc("GET /secrets/{name}/{version}", s.getSecret)
+	mux.HandleFunc("PATCH /secrets/{name}/{version}", s.updateSecret)
+	mux.HandleFunc("PATCH /secrets/{name}/{$}", s.updateSecret)
+	mux.HandleFunc("POST /secrets/{name}/backup", s.backupSecret)
+	mux.HandleFunc("POST /secrets/restore", s.restoreSecret)
+	mux.HandleFunc("GET /deletedsecrets", s.listDeleted)
+	mux.HandleFunc("GET /deletedsecrets/{name}", s.getDeleted)
+	mux.HandleFunc("DELETE /deletedsecrets/{name}", s.purgeDeleted)
+	mux.HandleFunc("POST /deletedsecrets/{name}/recover", s.recoverDeleted)
+	s.mux = mux
+
+	return s
+}
+
+// ServeHTTP answers requests without a bearer token with the challenge the
+// azsecrets client expects and dispatches everything else to the API.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	token, ok := strings.CutPrefix(auth, "Bearer ")
+	if !ok || (s.options.Token != "" && token != s.options.Token) {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer authorization="https://login.microsoftonline.com/%s", resource="%s"`, s.options.Tenant, s.options.Resource))
+		writeError(w, http.StatusUnauthorized, "Unauthorized", "AKV10000: Request is missing a Bearer or PoP token.")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.mux.ServeHTTP(w, r)
+}
+
+type attributes struct {
+	Enabled         *bool  `json:"enabled,omitempty"`
+	NotBefore       *int64 `json:"nbf,omitempty"`
+	Expires         *int64 `json:"exp,omitempty"`
+	Created         *int64 `json:"created,omitempty"`
+	Updated         *int64 `json:"updated,omitempty"`
+	RecoveryLevel   string `json:"recoveryLevel,omitempty"`
+	RecoverableDays int    `json:"recoverableDays,omitempty"`
+}
+
+type secretBundle struct {
+	ID                 string            `json:"id"`
+	Value              *string           `json:"value,omitempty"`
+	ContentType        string            `json:"contentType,omitempty"`
+	Attributes         attributes        `json:"attributes"`
+	Tags               map[string]string `json:"tags,omitempty"`
+	RecoveryID         string            `json:"recoveryId,omitempty"`
+	DeletedDate        *int64            `json:"deletedDate,omitempty"`
+	ScheduledPurgeDate *int64            `json:"scheduledPurgeDate,omitempty"`
+}
+
+type secretParameters struct {
+	Value       *string           `json:"value"`
+	ContentType *string           `json:"contentType"`
+	Attributes  *attributes       `json:"attributes"`
+	Tags        map[string]string `json:"tags"`
+}
+
+type listResult struct {
+	Value    []secretBundle `json:"value"`
+	NextLink *string        `json:"nextLink"`
+}
+
+type backupBlob struct {
+	Value string `json:"value"`
+}
+
+func (s *Server) baseURL(r *http.Request) string {
+	scheme := "https"
+	if r.TLS == nil {
+		scheme = "http"
+	}
+
+	return scheme + "://" + r.Host
+}
+
+func (s *Server) bundle(r *http.Request, e *Entry, v *Version, withValue bool) secretBundle {
+	enabled := v.Enabled
+	created := v.Created
+	updated := v.Updated
+	b := secretBundle{
+		ID:          s.baseURL(r) + "/secrets/" + e.Name + "/" + v.ID,
+		ContentType: v.ContentType,
+		Tags:        v.Tags,
+		Attributes: attributes{
+			Enabled:         &enabled,
+			NotBefore:       v.NotBefore,
+			Expires:         v.Expires,
+			Created:         &created,
+			Updated:         &updated,
+			RecoveryLevel:   recoveryLevel,
+			RecoverableDays: s.options.RetentionDays,
+		},
+	}
+
+	if withValue {
+		value := v.Value
+		b.Value = &value
+	}
+
+	if e.IsDeleted() {
+		b.RecoveryID = s.baseURL(r) + "/deletedsecrets/" + e.Name
+		b.DeletedDate = e.DeletedDate
+		b.ScheduledPurgeDate = e.ScheduledPurgeDate
+	}
+
+	return b
+}
+
+// lookup returns the live secret with the given name and writes a not
+// found response when there is none.
+func (s *Server) lookup(w http.ResponseWriter, name string) *Entry {
+	e, err := s.store.Get(name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return nil
+	}
+
+	if e == nil || e.IsDeleted() || e.Current() == nil {
+		writeError(w, http.StatusNotFound, "SecretNotFound", fmt.Sprintf("A secret with (name/id) %s was not found in this key vault.", name))
+		return nil
+	}
+
+	return e
+}
+
+func (s *Server) lookupDeleted(w http.ResponseWriter, name string) *Entry {
+	e, err := s.store.Get(name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return nil
+	}
+
+	if e == nil || !e.IsDeleted() {
+		writeError(w, http.StatusNotFound, "SecretNotFound", fmt.Sprintf("Deleted Secret not found: %s", name))
+		return nil
+	}
+
+	return e
+}
+
+func (s *Server) getSecret(w http.ResponseWriter, r *http.Request) {
+	e := s.lookup(w, r.PathValue("name"))
+	if e == nil {
+		return
+	}
+
+	v := e.Version(r.PathValue("version"))
+	if v == nil {
+		writeError(w, http.StatusNotFound, "SecretNotFound", fmt.Sprintf("A secret with (name/id) %s/%s was not found in this key vault.", e.Name, r.PathValue("version")))
+		return
+	}
+
+	if !v.Enabled {
+		writeError(w, http.StatusForbidden, "Forbidden", "Operation get is not allowed on a disabled secret.")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.bundle(r, e, v, true))
+}
+
+func (s *Server) setSecret(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !validName(name) {
+		writeError(w, http.StatusBadRequest, "BadParameter", "The request URI contains an invalid name: "+name)
+		return
+	}
+
+	params := secretParameters{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil || params.Value == nil {
+		writeError(w, http.StatusBadRequest, "BadParameter", "Property value is required.")
+		return
+	}
+
+	e, err := s.store.Get(name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+
+	if e != nil && e.IsDeleted() {
+		writeError(w, http.StatusConflict, "Conflict", fmt.Sprintf("Secret %s is currently in a deleted but recoverable state, and its name cannot be reused; in this state, the secret can only be recovered or purged.", name))
+		return
+	}
+
+	if e == nil {
+		e = &Entry{Name: name}
+	}
+
+	now := s.now().Unix()
+	v := &Version{
+		ID:      newVersion(),
+		Value:   *params.Value,
+		Enabled: true,
+		Created: now,
+		Updated: now,
+		Tags:    params.Tags,
+	}
+
+	if params.ContentType != nil {
+		v.ContentType = *params.ContentType
+	}
+
+	if params.Attributes != nil {
+		if params.Attributes.Enabled != nil {
+			v.Enabled = *params.Attributes.Enabled
+		}
+		v.NotBefore = params.Attributes.NotBefore
+		v.Expires = params.Attributes.Expires
+	}
+
+	e.Versions = append(e.Versions, v)
+	if err := s.store.Put(e); err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.bundle(r, e, v, true))
+}
+
+func (s *Server) updateSecret(w http.ResponseWriter, r *http.Request) {
+	e := s.lookup(w, r.PathValue("name"))
+	if e == nil {
+		return
+	}
+
+	v := e.Version(r.PathValue("version"))
+	if v == nil {
+		writeError(w, http.StatusNotFound, "SecretNotFound", fmt.Sprintf("A secret with (name/id) %s/%s was not found in this key vault.", e.Name, r.PathValue("version")))
+		return
+	}
+
+	params := secretParameters{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		writeError(w, http.StatusBadRequest, "BadParameter", err.Error())
+		return
+	}
+
+	if params.ContentType != nil {
+		v.ContentType = *params.ContentType
+	}
+
+	if params.Attributes != nil {
+		if params.Attributes.Enabled != nil {
+			v.Enabled = *params.Attributes.Enabled
+		}
+		if params.Attributes.NotBefore != nil {
+			v.NotBefore = params.Attributes.NotBefore
+		}
+		if params.Attributes.Expires != nil {
+			v.Expires = params.Attributes.Expires
+		}
+	}
+
+	if params.Tags != nil {
+		v.Tags = params.Tags
+	}
+
+	v.Updated = s.now().Unix()
+	if err := s.store.Put(e); err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.bundle(r, e, v, false))
+}
+
+func (s *Server) listSecrets(w http.ResponseWriter, r *http.Request) {
+	entries, err := s.store.List()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+
+	result := listResult{Value: []secretBundle{}}
+	for _, e := range entries {
+		if e.IsDeleted() || e.Current() == nil {
+			continue
+		}
+
+		result.Value = append(result.Value, s.bundle(r, e, e.Current(), false))
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) listVersions(w http.ResponseWriter, r *http.Request) {
+	e := s.lookup(w, r.PathValue("name"))
+	if e == nil {
+		return
+	}
+
+	result := listResult{Value: []secretBundle{}}
+	for _, v := range e.Versions {
+		result.Value = append(result.Value, s.bundle(r, e, v, false))
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) deleteSecret(w http.ResponseWriter, r *http.Request) {
+	e := s.lookup(w, r.PathValue("name"))
+	if e == nil {
+		return
+	}
+
+	now := s.now()
+	deleted := now.Unix()
+	purge := now.AddDate(0, 0, s.options.RetentionDays).Unix()
+	e.DeletedDate = &deleted
+	e.ScheduledPurgeDate = &purge
+
+	if err := s.store.Put(e); err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.bundle(r, e, e.Current(), false))
+}
+
+func (s *Server) listDeleted(w http.ResponseWriter, r *http.Request) {
+	entries, err := s.store.List()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+
+	result := listResult{Value: []secretBundle{}}
+	for _, e := range entries {
+		if !e.IsDeleted() || e.Current() == nil {
+			continue
+		}
+
+		result.Value = append(result.Value, s.bundle(r, e, e.Current(), false))
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) getDeleted(w http.ResponseWriter, r *http.Request) {
+	e := s.lookupDeleted(w, r.PathValue("name"))
+	if e == nil {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.bundle(r, e, e.Current(), false))
+}
+
+func (s *Server) purgeDeleted(w http.ResponseWriter, r *http.Request) {
+	e := s.lookupDeleted(w, r.PathValue("name"))
+	if e == nil {
+		return
+	}
+
+	if err := s.store.Remove(e.Name); err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) recoverDeleted(w http.ResponseWriter, r *http.Request) {
+	e := s.lookupDeleted(w, r.PathValue("name"))
+	if e == nil {
+		return
+	}
+
+	e.DeletedDate = nil
+	e.ScheduledPurgeDate = nil
+	if err := s.store.Put(e); err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.bundle(r, e, e.Current(), false))
+}
+
+// backupSecret returns every version of the secret. Unlike key vault the
+// blob is not encrypted; it is only meant for local use.
+func (s *Server) backupSecret(w http.ResponseWriter, r *http.Request) {
+	e := s.lookup(w, r.PathValue("name"))
+	if e == nil {
+		return
+	}
+
+	bits, err := json.Marshal(e)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, backupBlob{Value: base64.RawURLEncoding.EncodeToString(bits)})
+}
+
+func (s *Server) restoreSecret(w http.ResponseWriter, r *http.Request) {
+	blob := backupBlob{}
+	if err := json.NewDecoder(r.Body).Decode(&blob); err != nil {
+		writeError(w, http.StatusBadRequest, "BadParameter", err.Error())
+		return
+	}
+
+	bits, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(blob.Value, "="))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BadParameter", "Backup blob contains invalid or corrupt version.")
+		return
+	}
+
+	e := &Entry{}
+	if err := json.Unmarshal(bits, e); err != nil || e.Name == "" || len(e.Versions) == 0 {
+		writeError(w, http.StatusBadRequest, "BadParameter", "Backup blob contains invalid or corrupt version.")
+		return
+	}
+
+	existing, err := s.store.Get(e.Name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+
+	if existing != nil {
+		writeError(w, http.StatusConflict, "Conflict", fmt.Sprintf("Secret %s already exists", e.Name))
+		return
+	}
+
+	e.DeletedDate = nil
+	e.ScheduledPurgeDate = nil
+	if err := s.store.Put(e); err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.bundle(r, e, e.Current(), false))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]any{
+		"error": map[string]string{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
+
+func newVersion() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func validName(name string) bool {
+	if len(name) == 0 || len(name) > 127 {
+		return false
+	}
+
+	for _, r := range name {
+		if !(r == '-' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')) {
+			return false
+		}
+	}
+
+	return true
+}
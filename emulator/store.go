@@ -0,0 +1,88 @@
+package emulator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Version is a single version of a secret.
+type Version struct {
+	ID          string            `json:"id"`
+	Value       string            `json:"value"`
+	ContentType string            `json:"contentType,omitempty"`
+	Enabled     bool              `json:"enabled"`
+	NotBefore   *int64            `json:"nbf,omitempty"`
+	Expires     *int64            `json:"exp,omitempty"`
+	Created     int64             `json:"created"`
+	Updated     int64             `json:"updated"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+// Entry is a secret with all of its versions. The last version is the
+// current one. A soft deleted secret has DeletedDate set.
+type Entry struct {
+	Name               string     `json:"name"`
+	Versions           []*Version `json:"versions"`
+	DeletedDate        *int64     `json:"deletedDate,omitempty"`
+	ScheduledPurgeDate *int64     `json:"scheduledPurgeDate,omitempty"`
+}
+
+// Current returns the latest version of the secret.
+func (e *Entry) Current() *Version {
+	if len(e.Versions) == 0 {
+		return nil
+	}
+
+	return e.Versions[len(e.Versions)-1]
+}
+
+// Version returns the version with the given id, or the current version
+// when id is empty.
+func (e *Entry) Version(id string) *Version {
+	if id == "" {
+		return e.Current()
+	}
+
+	for _, v := range e.Versions {
+		if strings.EqualFold(v.ID, id) {
+			return v
+		}
+	}
+
+	return nil
+}
+
+// IsDeleted reports whether the secret is soft deleted.
+func (e *Entry) IsDeleted() bool {
+	return e.DeletedDate != nil
+}
+
+// Store persists the secrets of the emulated vault. Names are compared
+// case insensitively, as they are by key vault.
+type Store interface {
+	// Get returns the entry with the given name or nil when it does
+	// not exist.
+	Get(name string) (*Entry, error)
+	Put(entry *Entry) error
+	Remove(name string) error
+	List() ([]*Entry, error)
+	Close() error
+}
+
+// OpenStore opens the store for path. Files ending in .db or .bolt are
+// opened as bolt databases, anything else as a JSON file.
+func OpenStore(path string) (Store, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".db", ".bolt":
+		return OpenBoltStore(path)
+	case ".json", "":
+		return OpenJSONStore(path)
+	}
+
+	return nil, fmt.Errorf("unsupported store file %s: use .json, .db or .bolt", path)
+}
+
+func storeKey(name string) string {
+	return strings.ToLower(name)
+}
@@ -0,0 +1,87 @@
+package emulator
+
+import (
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var secretsBucket = []byte("secrets")
+
+// BoltStore keeps the secrets in a bolt database with one JSON encoded
+// entry per secret.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// OpenBoltStore opens or creates the bolt database at path.
+func OpenBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(secretsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+func (s *BoltStore) Get(name string) (*Entry, error) {
+	var entry *Entry
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bits := tx.Bucket(secretsBucket).Get([]byte(storeKey(name)))
+		if bits == nil {
+			return nil
+		}
+
+		entry = &Entry{}
+		return json.Unmarshal(bits, entry)
+	})
+
+	return entry, err
+}
+
+func (s *BoltStore) Put(entry *Entry) error {
+	bits, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(secretsBucket).Put([]byte(storeKey(entry.Name)), bits)
+	})
+}
+
+func (s *BoltStore) Remove(name string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(secretsBucket).Delete([]byte(storeKey(name)))
+	})
+}
+
+func (s *BoltStore) List() ([]*Entry, error) {
+	list := []*Entry{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(secretsBucket).ForEach(func(k, v []byte) error {
+			entry := &Entry{}
+			if err := json.Unmarshal(v, entry); err != nil {
+				return err
+			}
+			list = append(list, entry)
+			return nil
+		})
+	})
+
+	return list, err
+}
+
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
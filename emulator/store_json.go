@@ -0,0 +1,129 @@
+package emulator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// JSONStore keeps the secrets in memory and writes them to a JSON file
+// after every change.
+type JSONStore struct {
+	path    string
+	mu      sync.Mutex
+	entries map[string]*Entry
+}
+
+// OpenJSONStore loads the JSON file at path. The file is created on the
+// first write when it does not exist.
+func OpenJSONStore(path string) (*JSONStore, error) {
+	s := &JSONStore{path: path, entries: map[string]*Entry{}}
+
+	bits, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bits) == 0 {
+		return s, nil
+	}
+
+	entries := []*Entry{}
+	if err := json.Unmarshal(bits, &entries); err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		s.entries[storeKey(e.Name)] = e
+	}
+
+	return s, nil
+}
+
+func (s *JSONStore) Get(name string) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.entries[storeKey(name)], nil
+}
+
+func (s *JSONStore) Put(entry *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[storeKey(entry.Name)] = entry
+	return s.save()
+}
+
+func (s *JSONStore) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, storeKey(name))
+	return s.save()
+}
+
+func (s *JSONStore) List() ([]*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.list(), nil
+}
+
+func (s *JSONStore) Close() error {
+	return nil
+}
+
+func (s *JSONStore) list() []*Entry {
+	list := make([]*Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		list = append(list, e)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return storeKey(list[i].Name) < storeKey(list[j].Name)
+	})
+
+	return list
+}
+
+// save writes the file through a temporary file so that a crash never
+// leaves a partially written store behind.
+func (s *JSONStore) save() error {
+	bits, err := json.MarshalIndent(s.list(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".akv-emulator-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(bits); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}